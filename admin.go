@@ -1,16 +1,30 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"html/template"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/ianfoo/advent-of-code-2020/internal/calendar"
+	"github.com/ianfoo/advent-of-code-2020/internal/config"
+	"github.com/ianfoo/advent-of-code-2020/internal/fetch"
 	"github.com/ianfoo/advent-of-code-2020/internal/leaderboard"
+	"github.com/ianfoo/advent-of-code-2020/internal/scaffold"
+	"github.com/ianfoo/advent-of-code-2020/internal/session"
+	"github.com/ianfoo/advent-of-code-2020/internal/suite"
 	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
 )
 
 func main() {
@@ -22,6 +36,20 @@ func main() {
 
 func run() error {
 	app := &cli.App{
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "profile",
+				Usage:   "Configuration profile to use (default from $AOC_PROFILE or config file)",
+				Aliases: []string{"p"},
+			},
+			&cli.StringFlag{
+				Name:    "base-url",
+				Usage:   "Advent of Code site to talk to",
+				Value:   session.DefaultBaseURL,
+				EnvVars: []string{"AOC_BASE_URL"},
+				Hidden:  true,
+			},
+		},
 		Commands: []*cli.Command{
 			{
 				Name:    "bootstrap",
@@ -29,35 +57,90 @@ func run() error {
 				Usage:   "Bootstrap a new puzzle directory",
 				Flags: []cli.Flag{
 					&cli.UintFlag{
-						Name:    "day",
-						Usage:   "Number of day to bootstrap",
-						Aliases: []string{"d"},
+						Name:        "day",
+						Usage:       "Number of day to bootstrap",
+						Aliases:     []string{"d"},
+						DefaultText: "today's puzzle, or the next if it unlocks within the hour",
 					},
 					&cli.UintFlag{
-						Name:    "year",
-						Usage:   "Event year",
-						Aliases: []string{"y"},
-						Value:   uint(time.Now().Year()),
+						Name:        "year",
+						Usage:       "Event year",
+						Aliases:     []string{"y"},
+						DefaultText: "the current or most recent event",
 					},
 					&cli.StringFlag{
 						Name:    "template",
-						Usage:   "File to copy into destination",
+						Usage:   "Template directory to render into destination (default from $AOC_TEMPLATE or config)",
 						Aliases: []string{"t"},
-						Value:   filepath.Join("templates", "puzzle.go.tmpl"),
 					},
 					&cli.StringFlag{
 						Name:  "puzzle-root",
-						Usage: "Top-level puzzle directory",
-						Value: "puzzles",
+						Usage: "Top-level puzzle directory (default from $AOC_PUZZLE_ROOT or config)",
 					},
 					&cli.BoolFlag{
 						Name:    "force",
 						Aliases: []string{"f"},
-						Usage:   "Force bootstrap even if directory/file already exists",
+						Usage:   "Overwrite files that already exist instead of skipping them",
+					},
+					&cli.BoolFlag{
+						Name:    "wait",
+						Aliases: []string{"w"},
+						Usage:   "Wait for the puzzle to unlock, then download its input",
 					},
 				},
 				Action: BootstrapNewDay,
 			},
+			{
+				Name:  "run",
+				Usage: "Run puzzle solutions in any language with their inputs, and check the answers",
+				Flags: []cli.Flag{
+					&cli.UintFlag{
+						Name:        "day",
+						Usage:       "Number of day to run",
+						Aliases:     []string{"d"},
+						DefaultText: "every day",
+					},
+					&cli.UintFlag{
+						Name:        "year",
+						Usage:       "Event year",
+						Aliases:     []string{"y"},
+						DefaultText: "every year",
+					},
+					&cli.StringFlag{
+						Name:  "puzzle-root",
+						Usage: "Top-level puzzle directory (default from $AOC_PUZZLE_ROOT or config)",
+					},
+					&cli.StringFlag{
+						Name:  "input",
+						Usage: "Name of the input file in each puzzle directory",
+						Value: suite.DefaultInput,
+					},
+					&cli.StringFlag{
+						Name:  "impl",
+						Usage: "Use the implementation called `name` for the parts that have one",
+					},
+					&cli.BoolFlag{
+						Name:  "all-impls",
+						Usage: "Run every implementation of each part, check that they agree, and show how long each took",
+					},
+					&cli.StringFlag{
+						Name:  "log-level",
+						Usage: "Log `level` passed on to Go solutions",
+						Value: "warn",
+					},
+					&cli.StringSliceFlag{
+						Name:        "lang",
+						Usage:       "Only run solutions in these languages: go, rust, python or javascript",
+						DefaultText: "every language",
+					},
+					&cli.BoolFlag{
+						Name:  "record",
+						Usage: "Save the answers of puzzles that don't have any recorded yet",
+					},
+					formatFlag,
+				},
+				Action: RunPuzzles,
+			},
 			{
 				Name:    "leaderboard",
 				Aliases: []string{"lb"},
@@ -65,135 +148,524 @@ func run() error {
 				Flags: []cli.Flag{
 					&cli.UintFlag{
 						Name:  "id",
-						Usage: "Private leaderboard ID",
+						Usage: "Private leaderboard ID (default from $AOC_LEADERBOARD_ID or config)",
 					},
 					&cli.StringFlag{
-						Name:    "token",
-						Usage:   "Session token value",
-						EnvVars: []string{"AOC_SESSION_TOKEN"},
+						Name:  "token",
+						Usage: "Session token value (default from $AOC_SESSION_TOKEN or config)",
 					},
 					&cli.UintFlag{
-						Name:  "year",
-						Usage: "Event year for leaderboard",
-						Value: uint(time.Now().Year()),
+						Name:        "year",
+						Usage:       "Event year for leaderboard",
+						DefaultText: "the current or most recent event",
 					},
+					formatFlag,
 				},
 				Action: DisplayLeaderboard,
 			},
+			{
+				Name:   "login",
+				Usage:  "Validate a session cookie and save it for later commands",
+				Action: Login,
+			},
+			{
+				Name:   "whoami",
+				Usage:  "Show the logged-in user and when the session token expires",
+				Action: WhoAmI,
+			},
+			{
+				Name:  "config",
+				Usage: "Inspect configuration",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "show",
+						Usage:  "Print resolved settings and where each came from",
+						Flags:  []cli.Flag{formatFlag},
+						Action: ShowConfig,
+					},
+				},
+			},
 		},
 	}
 
 	return app.Run(os.Args)
 }
 
-// BootstrapNewDay creates a new directory and starting file for a new day
-// of Advent of Code, rendering a template with optional placeholders.
-// The us
-func BootstrapNewDay(c *cli.Context) error {
-
-	determineLikelyDay := func() (int, error) {
-		// Advent of Code releases new puzzles at midnight in the Eastern time
-		// zone of the USA.
-		loc, err := time.LoadLocation("America/New_York")
-		if err != nil {
-			return 0, fmt.Errorf("cannot determine day to init: %w", err)
-		}
+var formatFlag = &cli.StringFlag{
+	Name:  "format",
+	Usage: "Output format, text or json (default from $AOC_OUTPUT_FORMAT or config)",
+}
 
-		now := time.Now().In(loc)
+// loadSettings resolves configuration for the selected profile, then applies
+// environment variables and any flags given on the command line.
+func loadSettings(c *cli.Context) (config.Settings, error) {
+	s, err := config.Load(c.String("profile"))
+	if err != nil {
+		return config.Settings{}, fmt.Errorf("loading config: %w", err)
+	}
 
-		// If it's within an hour of the new puzzle dropping, set up for
-		// the next day. Otherwise set up for the current day.
-		day := now.Day()
-		if now.Hour() == 23 {
-			fmt.Println("Assuming bootstrap is for next day because of proximity")
-			day++
+	// Environment variables are applied first, so that flags given on the
+	// command line win over them.
+	for _, o := range []struct {
+		dst       *config.Setting
+		flag, env string
+	}{
+		{&s.TokenSource, "token", leaderboard.EnvVarAoCSession},
+		{&s.LeaderboardIDs, "id", "AOC_LEADERBOARD_ID"},
+		{&s.PuzzleRoot, "puzzle-root", "AOC_PUZZLE_ROOT"},
+		{&s.Template, "template", "AOC_TEMPLATE"},
+		{&s.OutputFormat, "format", "AOC_OUTPUT_FORMAT"},
+	} {
+		o.dst.Override(os.Getenv(o.env), config.SourceEnv)
+		for _, local := range c.LocalFlagNames() {
+			if local == o.flag {
+				o.dst.Override(fmt.Sprint(c.Value(o.flag)), config.SourceFlag)
+			}
 		}
+	}
 
-		return day, nil
+	if err := s.Validate(); err != nil {
+		return config.Settings{}, err
+	}
+	return s, nil
+}
+
+// BootstrapNewDay creates a new directory and starting files for a new day
+// of Advent of Code, rendering each file in the template directory with
+// optional placeholders.
+func BootstrapNewDay(c *cli.Context) error {
+	settings, err := loadSettings(c)
+	if err != nil {
+		return err
 	}
 
 	var (
 		year         = int(c.Uint("year"))
 		day          = int(c.Uint("day"))
-		templatePath = c.String("template")
-		puzzleRoot   = c.String("puzzle-root")
+		templatePath = settings.Template.Value
+		puzzleRoot   = settings.PuzzleRoot.Value
 		noClobber    = !c.Bool("force")
 	)
-	if day == 0 {
-		likely, err := determineLikelyDay()
-		if err != nil {
-			return err
-		}
-		day = likely
+	event, day, err := chooseEventAndDay(year, day, time.Now())
+	if err != nil {
+		return err
 	}
+	year = event.Year
 
 	var (
-		dayStr    = fmt.Sprintf("%02d", day)
-		dayDir    = "day-" + dayStr
-		targetDir = filepath.Join(puzzleRoot, strconv.Itoa(year), dayDir)
-	)
-	if err := os.MkdirAll(targetDir, os.FileMode(os.FileMode(0755))); err != nil {
-		return fmt.Errorf("creating destination directory: %w", err)
-	}
-	var (
-		fileName = dayDir + ".go"
-		filePath = filepath.Join(targetDir, fileName)
+		data      = scaffold.NewData(year, day)
+		targetDir = filepath.Join(puzzleRoot, strconv.Itoa(year), data.DayDir)
 	)
-	if _, err := os.Stat(filePath); err == nil && noClobber {
-		return fmt.Errorf("target file %s already exists: aborting", filePath)
+	fmt.Printf("rendering template %s into %s\n", templatePath, targetDir)
+	results, err := scaffold.Render(templatePath, targetDir, data, !noClobber)
+	for _, r := range results {
+		if r.Skipped {
+			fmt.Printf("  skipped %s: already exists\n", r.Target)
+			continue
+		}
+		fmt.Printf("  wrote %s\n", r.Target)
 	}
-	fmt.Printf("rendering template %s into %s\n", templatePath, filePath)
-	tmpl, err := template.New("aoc-template").ParseFiles(templatePath)
 	if err != nil {
-		return fmt.Errorf("cannot read template: %w", err)
+		return err
 	}
 
-	f, err := os.Create(filePath)
+	if !c.Bool("wait") {
+		return nil
+	}
+	token, err := settings.Token()
 	if err != nil {
-		return fmt.Errorf("create output file: %w", err)
+		return err
+	}
+	warnIfLoggedOut(c, token)
+	inputPath := filepath.Join(targetDir, "input.txt")
+	if fi, err := os.Stat(inputPath); err == nil && fi.Size() > 0 && noClobber {
+		return fmt.Errorf("input file %s already exists: aborting", inputPath)
+	}
+	if err := fetchInputWhenUnlocked(c, token, event, day, inputPath); err != nil {
+		return err
 	}
-	defer f.Close()
+	fmt.Printf("Ready! cd %s && go run . < input.txt\n", targetDir)
+	return nil
+}
 
-	tmplData := struct {
-		Day, Year int
-	}{
-		Day:  day,
-		Year: year,
+// chooseEventAndDay fills in the year and day to bootstrap when they're not
+// given, and rejects puzzles that don't exist.
+func chooseEventAndDay(year, day int, now time.Time) (calendar.Event, int, error) {
+	if day == 0 {
+		event, likely, early, err := calendar.LikelyDay(now)
+		if err != nil {
+			return calendar.Event{}, 0, err
+		}
+		if year != 0 && year != event.Year {
+			return calendar.Event{}, 0, fmt.Errorf("the %d event is not in progress: specify a day", year)
+		}
+		if early {
+			fmt.Println("Assuming bootstrap is for next day because of proximity")
+		}
+		year, day = event.Year, likely
 	}
-	templateName := filepath.Base(templatePath)
-	if err := tmpl.ExecuteTemplate(f, templateName, tmplData); err != nil {
-		return fmt.Errorf("rendering template: %w", err)
+	if year == 0 {
+		// Look slightly ahead so that bootstrapping day 1 just before it
+		// unlocks picks the new event.
+		latest, err := calendar.Latest(now.Add(calendar.Lead))
+		if err != nil {
+			return calendar.Event{}, 0, err
+		}
+		year = latest.Year
+	}
+	if err := calendar.ValidateAt(year, day, now); err != nil {
+		return calendar.Event{}, 0, err
+	}
+	event, err := calendar.EventFor(year)
+	return event, day, err
+}
+
+// fetchInputWhenUnlocked counts down on stderr until a puzzle unlocks, then
+// downloads its input to path. Interrupting the program stops the wait.
+func fetchInputWhenUnlocked(c *cli.Context, token string, event calendar.Event, day int, path string) error {
+	unlock := event.Unlock(day)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	go func() {
+		select {
+		case <-interrupt:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	client := fetch.NewClient()
+	client.BaseURL = strings.TrimSuffix(c.String("base-url"), "/")
+
+	err := client.WaitUntil(ctx, unlock, func(remaining time.Duration) {
+		fmt.Fprintf(os.Stderr, "\rday %d unlocks in %-12v", day, remaining.Round(time.Second))
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr)
+		return fmt.Errorf("waiting for unlock: %w", err)
 	}
+	fmt.Fprintf(os.Stderr, "\rday %d is unlocked, fetching input\n", day)
 
+	input, err := client.InputWithRetry(ctx, event.Year, day, token)
+	if err != nil {
+		return fmt.Errorf("fetching input: %w", err)
+	}
+	if err := ioutil.WriteFile(path, input, 0644); err != nil {
+		return fmt.Errorf("writing input: %w", err)
+	}
+	fmt.Printf("saved input to %s\n", path)
 	return nil
 }
 
+// DisplayLeaderboard prints the standings of each configured private
+// leaderboard, or of a leaderboard read from stdin when no leaderboard or
+// session token is available.
 func DisplayLeaderboard(c *cli.Context) error {
+	settings, err := loadSettings(c)
+	if err != nil {
+		return err
+	}
 	var (
-		year          = c.Uint("year")
-		leaderboardID = c.Uint("id")
-		token         = c.String("token")
-
-		lb  leaderboard.Leaderboard
-		err error
+		year   = c.Uint("year")
+		format = settings.OutputFormat.Value
+		now    = time.Now()
 	)
+	if year == 0 {
+		latest, err := calendar.Latest(now)
+		if err != nil {
+			return err
+		}
+		year = uint(latest.Year)
+	}
+	if err := calendar.ValidateAt(int(year), 1, now); err != nil {
+		return err
+	}
+	ids, err := settings.Leaderboards()
+	if err != nil {
+		return err
+	}
+	token, err := settings.Token()
+	if err != nil {
+		return err
+	}
 
-	// Read from stdin if missing required params.
-	if leaderboardID == 0 && token == "" {
-		lb, err = leaderboard.FromReader(os.Stdin)
+	// Read from stdin if there's no leaderboard to fetch, even with a stored
+	// session token.
+	if len(ids) == 0 {
+		lb, err := leaderboard.FromReader(os.Stdin)
 		if err != nil {
 			return fmt.Errorf("reading leaderboard: %w", err)
 		}
-		fmt.Println(lb)
-		return nil
+		return printLeaderboard(lb, format)
 	}
 
 	// Fetch from internet if ID and session spcified.
-	lb, err = leaderboard.Fetch(http.DefaultClient, year, leaderboardID, token)
-	if err != nil {
-		return fmt.Errorf("fetching leaderboard: %w", err)
+	warnIfLoggedOut(c, token)
+	for _, id := range ids {
+		fmt.Fprintf(os.Stderr, "fetching leaderboard %d for %d\n", id, year)
+		lb, err := leaderboard.Fetch(http.DefaultClient, year, id, token)
+		if err != nil {
+			return fmt.Errorf("fetching leaderboard %d: %w", id, err)
+		}
+		if err := printLeaderboard(lb, format); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
+func printLeaderboard(lb leaderboard.Leaderboard, format string) error {
+	if format == config.FormatJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(lb)
+	}
 	fmt.Println(lb)
 	return nil
 }
+
+// RunPuzzles runs each selected puzzle's solution with its input, one at a
+// time, and prints a table of the answers.
+func RunPuzzles(c *cli.Context) error {
+	settings, err := loadSettings(c)
+	if err != nil {
+		return err
+	}
+	puzzles, err := suite.Find(settings.PuzzleRoot.Value, int(c.Uint("year")), int(c.Uint("day")))
+	if err != nil {
+		return err
+	}
+
+	opts := suite.Options{
+		Input:  c.String("input"),
+		Args:   []string{"-log-level", c.String("log-level")},
+		Stderr: os.Stderr,
+	}
+	if c.Bool("all-impls") {
+		opts.Args = append(opts.Args, "-all-impls")
+	}
+	if impl := c.String("impl"); impl != "" {
+		opts.Args = append(opts.Args, "-impl", impl)
+	}
+
+	langs := make(map[string]bool)
+	for _, l := range c.StringSlice("lang") {
+		langs[l] = true
+	}
+	var (
+		reports []suite.Report
+		failed  int
+	)
+	for _, p := range puzzles {
+		solutions, err := suite.Detect(p.Dir)
+		if err != nil {
+			return err
+		}
+		for _, sol := range solutions {
+			if len(langs) > 0 && !langs[sol.Language.Name] {
+				continue
+			}
+			fmt.Fprintf(os.Stderr, "running %s in %s\n", p, sol.Language.Name)
+			rep := suite.Run(c.Context, p, sol, opts)
+			if rep.Failed() {
+				failed++
+			}
+			if c.Bool("record") {
+				recorded, err := suite.RecordAnswers(rep)
+				if err != nil {
+					return err
+				}
+				if recorded {
+					fmt.Fprintf(os.Stderr, "recorded answers for %s\n", p)
+					rep.Expected, _ = suite.LoadAnswers(p.Dir)
+				}
+			}
+			reports = append(reports, rep)
+		}
+	}
+
+	rows := suite.Rows(reports)
+	if settings.OutputFormat.Value == config.FormatJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(rows); err != nil {
+			return err
+		}
+	} else if err := suite.WriteTable(os.Stdout, rows); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d solutions failed", failed, len(reports))
+	}
+	return nil
+}
+
+// ShowConfig prints the resolved settings for the selected profile, along
+// with the source of each value.
+func ShowConfig(c *cli.Context) error {
+	settings, err := loadSettings(c)
+	if err != nil {
+		return err
+	}
+
+	// Never print a token itself: only describe where it comes from.
+	tokenSource := settings.TokenSource
+	if tokenSource.Value != config.TokenSourceSession &&
+		!strings.HasPrefix(tokenSource.Value, "env:") &&
+		!strings.HasPrefix(tokenSource.Value, "file:") {
+		tokenSource.Value = fmt.Sprintf("(literal token, %d chars)", len(tokenSource.Value))
+	}
+	rows := []struct {
+		Name string `json:"name"`
+		config.Setting
+	}{
+		{"profile", settings.Profile},
+		{"token_source", tokenSource},
+		{"leaderboard_ids", settings.LeaderboardIDs},
+		{"puzzle_root", settings.PuzzleRoot},
+		{"template", settings.Template},
+		{"output_format", settings.OutputFormat},
+	}
+
+	if settings.OutputFormat.Value == config.FormatJSON {
+		out := struct {
+			Files    []string    `json:"files"`
+			Settings interface{} `json:"settings"`
+		}{settings.Files, rows}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+
+	files := "(none)"
+	if len(settings.Files) > 0 {
+		files = strings.Join(settings.Files, ", ")
+	}
+	fmt.Printf("config files: %s\n\n", files)
+	for _, r := range rows {
+		v := r.Value
+		if v == "" {
+			v = "(unset)"
+		}
+		fmt.Printf("%-16s %-40s [%s]\n", r.Name, v, r.Source)
+	}
+	return nil
+}
+
+func sessionClient(c *cli.Context) session.Client {
+	client := session.NewClient()
+	client.BaseURL = strings.TrimSuffix(c.String("base-url"), "/")
+	return client
+}
+
+// warnIfLoggedOut checks a token before it's used for other requests, so that
+// an expired session is reported as such rather than as whatever confusing
+// response the site sends back for logged-out requests.
+func warnIfLoggedOut(c *cli.Context, token string) {
+	if _, err := sessionClient(c).Validate(token); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v: run \"admin login\" to refresh it\n", err)
+	}
+}
+
+// Login reads a session cookie value without echoing it, checks that the
+// site accepts it, and saves it for use by other commands.
+func Login(c *cli.Context) error {
+	token, err := readToken("Session cookie value: ")
+	if err != nil {
+		return fmt.Errorf("reading token: %w", err)
+	}
+	user, err := sessionClient(c).Validate(token)
+	if err != nil {
+		return err
+	}
+
+	path, err := config.SessionFilePath()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	stored := session.Stored{
+		Token:       token,
+		User:        user,
+		LoggedInAt:  now,
+		ValidatedAt: now,
+	}
+	if err := session.Save(path, stored); err != nil {
+		return err
+	}
+	fmt.Printf("Logged in as %s. Token saved to %s\n", user, path)
+	return nil
+}
+
+// readToken prompts for a secret on stderr. Input is not echoed if stdin is
+// a terminal; otherwise the first line of stdin is used, so a token can be
+// piped in.
+func readToken(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		return strings.TrimSpace(line), nil
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	b, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// WhoAmI reports which user the configured token belongs to, and roughly
+// when it will expire if it was saved by the login command.
+func WhoAmI(c *cli.Context) error {
+	settings, err := loadSettings(c)
+	if err != nil {
+		return err
+	}
+	token, err := settings.Token()
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return errors.New("not logged in: run \"admin login\"")
+	}
+	user, err := sessionClient(c).Validate(token)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Logged in as %s (token from %s)\n", user, settings.TokenSource.Source)
+
+	path, err := config.SessionFilePath()
+	if err != nil {
+		return err
+	}
+	stored, err := session.Load(path)
+	if err != nil {
+		return err
+	}
+	if stored.Token != token {
+		fmt.Println("Token expiry unknown: it was not saved by \"admin login\"")
+		return nil
+	}
+	var (
+		expires = stored.Expires()
+		left    = time.Until(expires).Round(time.Hour)
+	)
+	fmt.Printf("Logged in at %s\n", stored.LoggedInAt.Format(time.RFC1123))
+	if left <= 0 {
+		fmt.Printf("Token was expected to expire around %s, but still works\n", expires.Format("2006-01-02"))
+	} else {
+		fmt.Printf("Token expires around %s (in about %d days)\n", expires.Format("2006-01-02"), int(left.Hours()/24))
+	}
+
+	stored.User = user
+	stored.ValidatedAt = time.Now()
+	return session.Save(path, stored)
+}
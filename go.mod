@@ -2,4 +2,7 @@ module github.com/ianfoo/advent-of-code-2020
 
 go 1.15
 
-require github.com/urfave/cli/v2 v2.3.0
+require (
+	github.com/urfave/cli/v2 v2.3.0
+	golang.org/x/term v0.0.0-20201210144234-2321bbc49cbf
+)
@@ -0,0 +1,169 @@
+// Package answer holds puzzle answers, which are usually integers, sometimes
+// too big for an int64, and now and then a string, such as letters drawn on
+// a grid.
+package answer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kind is the type of value an answer holds.
+type Kind int
+
+const (
+	KindNone Kind = iota
+	KindInt
+	KindBig
+	KindString
+)
+
+// Answer is the answer to one part of a puzzle. The zero value is no answer
+// at all.
+type Answer struct {
+	kind Kind
+	i    int64
+	b    *big.Int
+	s    string
+}
+
+// Int returns an integer answer.
+func Int(n int) Answer {
+	return Int64(int64(n))
+}
+
+// Int64 returns an integer answer.
+func Int64(n int64) Answer {
+	return Answer{kind: KindInt, i: n}
+}
+
+// Big returns an integer answer that may not fit in an int64. Answers that do
+// fit are stored as an int64.
+func Big(n *big.Int) Answer {
+	if n.IsInt64() {
+		return Int64(n.Int64())
+	}
+	return Answer{kind: KindBig, b: new(big.Int).Set(n)}
+}
+
+// Text returns a string answer.
+func Text(s string) Answer {
+	return Answer{kind: KindString, s: s}
+}
+
+// Parse turns the text of an answer back into an Answer: an integer if it is
+// written the way an integer answer would be, and a string otherwise, so
+// that text like "0123" or "+5" keeps its form.
+func Parse(s string) Answer {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Answer{}
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil && strconv.FormatInt(n, 10) == s {
+		return Int64(n)
+	}
+	if n, ok := new(big.Int).SetString(s, 10); ok && n.String() == s {
+		return Big(n)
+	}
+	return Text(s)
+}
+
+// Kind is the type of value the answer holds.
+func (a Answer) Kind() Kind { return a.kind }
+
+// IsZero reports whether a is no answer at all.
+func (a Answer) IsZero() bool { return a.kind == KindNone }
+
+// Int64 returns the answer as an int64, and false if it isn't an integer
+// that fits in one.
+func (a Answer) Int64() (int64, bool) {
+	return a.i, a.kind == KindInt
+}
+
+// String formats the answer the way it would be typed into the puzzle page.
+func (a Answer) String() string {
+	switch a.kind {
+	case KindInt:
+		return strconv.FormatInt(a.i, 10)
+	case KindBig:
+		return a.b.String()
+	case KindString:
+		return a.s
+	}
+	return ""
+}
+
+// Equal reports whether a and b are the same answer. Integers are equal if
+// they have the same value, however they are stored.
+func (a Answer) Equal(b Answer) bool {
+	if a.kind != b.kind {
+		return false
+	}
+	switch a.kind {
+	case KindInt:
+		return a.i == b.i
+	case KindBig:
+		return a.b.Cmp(b.b) == 0
+	case KindString:
+		return a.s == b.s
+	}
+	return true
+}
+
+// MarshalText encodes the answer as its String form.
+func (a Answer) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText decodes an answer encoded by MarshalText.
+func (a *Answer) UnmarshalText(text []byte) error {
+	*a = Parse(string(text))
+	return nil
+}
+
+// FormatLine returns the line printed for the answer to a part.
+func FormatLine(part int, a Answer) string {
+	return fmt.Sprintf("Part %d: %s", part, a)
+}
+
+var lineRegexp = regexp.MustCompile(`^Part (\d+): (.*)$`)
+
+// ParseLines reads answers from "Part N: answer" lines, as printed by
+// solutions. Other lines are ignored.
+func ParseLines(r io.Reader) (map[int]Answer, error) {
+	var (
+		s       = bufio.NewScanner(r)
+		answers = make(map[int]Answer)
+	)
+	for s.Scan() {
+		m := lineRegexp.FindStringSubmatch(strings.TrimSpace(s.Text()))
+		if m == nil {
+			continue
+		}
+		part, err := strconv.Atoi(m[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid part number %q", m[1])
+		}
+		answers[part] = Parse(m[2])
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return answers, nil
+}
+
+// Parts returns the part numbers in answers, in order.
+func Parts(answers map[int]Answer) []int {
+	parts := make([]int, 0, len(answers))
+	for p := range answers {
+		parts = append(parts, p)
+	}
+	sort.Ints(parts)
+	return parts
+}
@@ -0,0 +1,75 @@
+package answer
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	tt := []struct {
+		name string
+		a, b Answer
+		want bool
+	}{
+		{name: "same int", a: Int(42), b: Int64(42), want: true},
+		{name: "different int", a: Int(42), b: Int(43)},
+		{name: "small big is an int", a: Big(big.NewInt(7)), b: Int(7), want: true},
+		{name: "same big", a: Big(huge), b: Parse(huge.String()), want: true},
+		{name: "big and int", a: Big(huge), b: Int(7)},
+		{name: "same string", a: Text("EZKLAJHP"), b: Parse("EZKLAJHP"), want: true},
+		{name: "string is not a number", a: Text("12"), b: Int(12)},
+		{name: "leading zeros", a: Text("0123"), b: Parse("0123"), want: true},
+		{name: "none", a: Answer{}, b: Parse(""), want: true},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.a.Equal(tc.b); got != tc.want {
+				t.Fatalf("expected %s == %s to be %t", tc.a, tc.b, tc.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tt := []struct {
+		in   string
+		kind Kind
+	}{
+		{in: "600689120448303", kind: KindInt},
+		{in: "-5", kind: KindInt},
+		{in: "99999999999999999999", kind: KindBig},
+		{in: "abc,def", kind: KindString},
+		{in: "0123", kind: KindString},
+		{in: "+5", kind: KindString},
+		{in: "-0", kind: KindString},
+		{in: "099999999999999999999", kind: KindString},
+		{in: "  ", kind: KindNone},
+	}
+	for _, tc := range tt {
+		t.Run(tc.in, func(t *testing.T) {
+			a := Parse(tc.in)
+			if a.Kind() != tc.kind {
+				t.Fatalf("expected kind %d, but got %d", tc.kind, a.Kind())
+			}
+			if got, want := a.String(), strings.TrimSpace(tc.in); got != want {
+				t.Fatalf("expected %q, but got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestParseLines(t *testing.T) {
+	in := "some chatter\nPart 1: 866\nPart 2: LEGJUPRE\n"
+	got, err := ParseLines(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || !got[1].Equal(Int(866)) || !got[2].Equal(Text("LEGJUPRE")) {
+		t.Fatalf("unexpected answers: %v", got)
+	}
+	if line := FormatLine(1, got[1]); line != "Part 1: 866" {
+		t.Fatalf("expected %q, but got %q", "Part 1: 866", line)
+	}
+}
@@ -0,0 +1,287 @@
+// Package automaton runs cellular automata: every cell of a state changes at
+// once, according to a rule that looks at the cell and its neighbors, over and
+// over until the state settles down or starts repeating.
+//
+// States may be dense, a fixed box of cells in up to MaxDims dimensions, or
+// sparse, an unbounded set of live cells.
+package automaton
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxDims is the most dimensions a state can have.
+const MaxDims = 4
+
+// Coord is the location of a cell. Coordinates past the state's number of
+// dimensions are always zero.
+type Coord [MaxDims]int
+
+// Add returns the sum of c and d.
+func (c Coord) Add(d Coord) Coord {
+	for i := range c {
+		c[i] += d[i]
+	}
+	return c
+}
+
+// State is the set of cells an automaton works on.
+type State interface {
+	// Dims is the number of dimensions of the state.
+	Dims() int
+
+	// Get returns the value of the cell at c.
+	Get(c Coord) rune
+
+	// Set changes the value of the cell at c.
+	Set(c Coord, r rune)
+
+	// Candidates calls fn for every cell that might have a new value after
+	// the next step.
+	Candidates(nb Neighborhood, fn func(c Coord))
+
+	// Empty returns a state of the same shape with every cell blank, to
+	// build the next step in.
+	Empty() State
+
+	// Key returns a string that is the same for two states if and only if
+	// they hold the same cells.
+	Key() string
+}
+
+// Neighborhood returns the cells whose values the rule gets when deciding
+// the next value of the cell at c.
+type Neighborhood func(c Coord) []Coord
+
+// Rule returns the next value of a cell from its current value and the
+// values of its neighbors. The neighbors slice is reused from cell to cell,
+// so the rule must not keep it.
+type Rule func(cell rune, neighbors []rune) rune
+
+// Moore returns the neighborhood of every cell that touches c, including at
+// a corner, in the given number of dimensions: 8 cells in 2D, 26 in 3D, and
+// 80 in 4D.
+func Moore(dims int) Neighborhood {
+	offsets := mooreOffsets(dims)
+	return func(c Coord) []Coord {
+		neighbors := make([]Coord, len(offsets))
+		for i, o := range offsets {
+			neighbors[i] = c.Add(o)
+		}
+		return neighbors
+	}
+}
+
+func mooreOffsets(dims int) []Coord {
+	offsets := []Coord{{}}
+	for d := 0; d < dims; d++ {
+		var next []Coord
+		for _, o := range offsets {
+			for delta := -1; delta <= 1; delta++ {
+				o[d] = delta
+				next = append(next, o)
+			}
+		}
+		offsets = next
+	}
+
+	// Drop the cell itself, which is all zeroes.
+	for i, o := range offsets {
+		if o == (Coord{}) {
+			return append(offsets[:i], offsets[i+1:]...)
+		}
+	}
+	return offsets
+}
+
+// Neighbors returns the values of the neighbors of c in s.
+func Neighbors(s State, nb Neighborhood, c Coord) []rune {
+	coords := nb(c)
+	values := make([]rune, len(coords))
+	for i, n := range coords {
+		values[i] = s.Get(n)
+	}
+	return values
+}
+
+// Automaton pairs a neighborhood with a rule. The neighborhood of each cell of
+// a dense state is worked out once and kept, so the Neighborhood must not be
+// changed after the first step, and an Automaton isn't safe to step from
+// more than one goroutine at once.
+type Automaton struct {
+	Neighborhood Neighborhood
+	Rule         Rule
+
+	table *neighborTable
+}
+
+// neighborTable lists the neighbors of each cell of a dense state by index
+// into its cells, or -1 for a neighbor outside the state. The neighbors of
+// cell i are neighbors[start[i]:start[i+1]].
+type neighborTable struct {
+	size      []int
+	start     []int
+	neighbors []int
+}
+
+// tableFor returns the neighbor table for states the size of d, working it
+// out if the last one was for a different size.
+func (a *Automaton) tableFor(d *Dense) *neighborTable {
+	if t := a.table; t != nil && sameSize(t.size, d.size) {
+		return t
+	}
+	t := &neighborTable{
+		size:  append([]int(nil), d.size...),
+		start: make([]int, 0, len(d.cells)+1),
+	}
+	for i := range d.cells {
+		t.start = append(t.start, len(t.neighbors))
+		for _, c := range a.Neighborhood(d.coord(i)) {
+			n, ok := d.index(c)
+			if !ok {
+				n = -1
+			}
+			t.neighbors = append(t.neighbors, n)
+		}
+	}
+	t.start = append(t.start, len(t.neighbors))
+	a.table = t
+	return t
+}
+
+func sameSize(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// stepDense is Step for a dense state, using the neighbor table.
+func (a *Automaton) stepDense(d *Dense) *Dense {
+	var (
+		t      = a.tableFor(d)
+		next   = &Dense{size: d.size, cells: make([]rune, len(d.cells))}
+		values []rune
+	)
+	for i, cell := range d.cells {
+		values = values[:0]
+		for _, n := range t.neighbors[t.start[i]:t.start[i+1]] {
+			if n < 0 {
+				values = append(values, Outside)
+				continue
+			}
+			values = append(values, d.cells[n])
+		}
+		next.cells[i] = a.Rule(cell, values)
+	}
+	return next
+}
+
+// Step returns the state after applying the rule to every cell of s once.
+// s is left unchanged.
+func (a *Automaton) Step(s State) State {
+	if d, ok := s.(*Dense); ok {
+		return a.stepDense(d)
+	}
+	next := s.Empty()
+	s.Candidates(a.Neighborhood, func(c Coord) {
+		next.Set(c, a.Rule(s.Get(c), Neighbors(s, a.Neighborhood, c)))
+	})
+	return next
+}
+
+// Iterator steps an automaton one generation at a time.
+type Iterator struct {
+	a     *Automaton
+	state State
+	step  int
+}
+
+// Iterate returns an iterator starting from s.
+func (a *Automaton) Iterate(s State) *Iterator {
+	return &Iterator{a: a, state: s}
+}
+
+// Next advances one step and returns the new state.
+func (it *Iterator) Next() State {
+	it.state = it.a.Step(it.state)
+	it.step++
+	return it.state
+}
+
+// State is the current state.
+func (it *Iterator) State() State { return it.state }
+
+// Step is how many steps have been taken.
+func (it *Iterator) Step() int { return it.step }
+
+// ErrLimit is returned when a run takes more steps than allowed without the
+// state repeating.
+var ErrLimit = errors.New("step limit reached without repeating")
+
+// Outcome describes how a run ended.
+type Outcome struct {
+	// State is the first state that was seen before.
+	State State
+
+	// Steps is how many steps were taken to reach State.
+	Steps int
+
+	// Start is the step at which State was first seen, so the cycle runs from
+	// Start to Steps.
+	Start int
+
+	// Period is the length of the cycle, 1 for a fixpoint.
+	Period int
+}
+
+// Fixpoint reports whether the run ended at a state that no longer changes.
+func (o Outcome) Fixpoint() bool { return o.Period == 1 }
+
+func (o Outcome) String() string {
+	if o.Fixpoint() {
+		return fmt.Sprintf("fixpoint after %d steps", o.Start)
+	}
+	return fmt.Sprintf("cycle of period %d starting at step %d", o.Period, o.Start)
+}
+
+// Run steps from s until some state repeats, giving up with ErrLimit after
+// limit steps. A limit of 0 means no limit.
+func (a *Automaton) Run(s State, limit int) (Outcome, error) {
+	var (
+		it   = a.Iterate(s)
+		seen = map[string]int{s.Key(): 0}
+	)
+	for limit == 0 || it.Step() < limit {
+		key := it.Next().Key()
+		if start, ok := seen[key]; ok {
+			return Outcome{
+				State:  it.State(),
+				Steps:  it.Step(),
+				Start:  start,
+				Period: it.Step() - start,
+			}, nil
+		}
+		seen[key] = it.Step()
+	}
+	return Outcome{State: it.State(), Steps: it.Step()}, ErrLimit
+}
+
+// Fixpoint steps from s until the state stops changing. It is an error for
+// the state to fall into a longer cycle instead.
+func (a *Automaton) Fixpoint(s State, limit int) (State, int, error) {
+	out, err := a.Run(s, limit)
+	if err != nil {
+		return nil, out.Steps, err
+	}
+	if !out.Fixpoint() {
+		return nil, out.Steps, fmt.Errorf("no fixpoint: %v", out)
+	}
+	return out.State, out.Start, nil
+}
@@ -0,0 +1,151 @@
+package automaton
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/grid"
+)
+
+// life is Conway's Game of Life, generalized to any number of dimensions by
+// counting live neighbors the same way.
+func life(cell rune, neighbors []rune) rune {
+	var live int
+	for _, n := range neighbors {
+		if n == Alive {
+			live++
+		}
+	}
+	switch {
+	case cell == Alive && (live == 2 || live == 3):
+		return Alive
+	case cell != Alive && live == 3:
+		return Alive
+	}
+	return Dead
+}
+
+func mustGrid(t *testing.T, lines ...string) *grid.Grid {
+	t.Helper()
+	g, err := grid.FromLines(lines)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return g
+}
+
+func TestMoore(t *testing.T) {
+	for dims, want := range map[int]int{1: 2, 2: 8, 3: 26, 4: 80} {
+		got := Moore(dims)(Coord{})
+		if len(got) != want {
+			t.Errorf("%dD: expected %d neighbors, but got %d", dims, want, len(got))
+		}
+		for _, c := range got {
+			if c == (Coord{}) {
+				t.Errorf("%dD: neighborhood includes the cell itself", dims)
+			}
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	a := &Automaton{Neighborhood: Moore(2), Rule: life}
+	tt := []struct {
+		name          string
+		state         State
+		start, period int
+	}{
+		{
+			name:   "block is a fixpoint",
+			state:  SparseFromGrid(mustGrid(t, "##", "##"), '#', 2),
+			start:  0,
+			period: 1,
+		},
+		{
+			name:   "blinker has period 2",
+			state:  SparseFromGrid(mustGrid(t, "...", "###", "..."), '#', 2),
+			start:  0,
+			period: 2,
+		},
+		{
+			name:   "lone cell dies, then stays dead",
+			state:  SparseFromGrid(mustGrid(t, "#"), '#', 2),
+			start:  1,
+			period: 1,
+		},
+		{
+			name:   "dense blinker",
+			state:  DenseFromGrid(mustGrid(t, ".....", "..#..", "..#..", "..#..", ".....")),
+			start:  0,
+			period: 2,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := a.Run(tc.state, 100)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.Start != tc.start || out.Period != tc.period {
+				t.Fatalf("expected start %d, period %d, but got %v", tc.start, tc.period, out)
+			}
+		})
+	}
+}
+
+func TestRunLimit(t *testing.T) {
+	// A glider never repeats on an infinite board.
+	glider := SparseFromGrid(mustGrid(t, ".#.", "..#", "###"), '#', 2)
+	a := &Automaton{Neighborhood: Moore(2), Rule: life}
+	if _, err := a.Run(glider, 20); !errors.Is(err, ErrLimit) {
+		t.Fatalf("expected ErrLimit, but got %v", err)
+	}
+	if _, _, err := a.Fixpoint(SparseFromGrid(mustGrid(t, "###"), '#', 2), 20); err == nil {
+		t.Fatal("expected error for cycle without fixpoint, but got none")
+	}
+}
+
+func TestIterateHigherDimensions(t *testing.T) {
+	// The Conway Cubes sample from 2020 day 17.
+	start := mustGrid(t,
+		".#.",
+		"..#",
+		"###",
+	)
+	tt := []struct {
+		dims int
+		want int
+	}{
+		{dims: 3, want: 112},
+		{dims: 4, want: 848},
+	}
+	for _, tc := range tt {
+		a := &Automaton{Neighborhood: Moore(tc.dims), Rule: life}
+		it := a.Iterate(SparseFromGrid(start, '#', tc.dims))
+		for it.Step() < 6 {
+			it.Next()
+		}
+		if got := it.State().(*Sparse).Len(); got != tc.want {
+			t.Errorf("%dD: expected %d live cells, but got %d", tc.dims, tc.want, got)
+		}
+	}
+}
+
+func TestDense(t *testing.T) {
+	d := NewDense('.', 2, 2, 2)
+	d.Set(Coord{1, 0, 1}, '#')
+	d.Set(Coord{5, 5, 5}, '#')
+	if got := d.Get(Coord{1, 0, 1}); got != '#' {
+		t.Errorf("expected '#', but got %q", got)
+	}
+	if got := d.Get(Coord{0, 0, 2}); got != Outside {
+		t.Errorf("expected Outside, but got %q", got)
+	}
+	if got := d.Count('#'); got != 1 {
+		t.Errorf("expected 1 '#', but got %d", got)
+	}
+	want := "[0]\n..\n..\n\n[1]\n.#\n.."
+	if got := d.String(); got != want {
+		t.Errorf("expected\n%s\nbut got\n%s", want, got)
+	}
+}
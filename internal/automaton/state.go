@@ -0,0 +1,260 @@
+package automaton
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/grid"
+)
+
+// Outside is the value of cells beyond the edges of a dense state.
+const Outside rune = 0
+
+// Dense is a box of cells with a fixed size in each dimension.
+type Dense struct {
+	size  []int
+	cells []rune
+}
+
+// NewDense returns a dense state of the given size, with every cell set to
+// fill.
+func NewDense(fill rune, size ...int) *Dense {
+	if len(size) == 0 || len(size) > MaxDims {
+		panic(fmt.Sprintf("automaton: dense state must have 1 to %d dimensions, but got %d", MaxDims, len(size)))
+	}
+	n := 1
+	for _, s := range size {
+		n *= s
+	}
+	cells := make([]rune, n)
+	for i := range cells {
+		cells[i] = fill
+	}
+	return &Dense{size: append([]int(nil), size...), cells: cells}
+}
+
+// DenseFromGrid returns a two dimensional dense state holding the cells of
+// g, with X as the first coordinate and Y as the second.
+func DenseFromGrid(g *grid.Grid) *Dense {
+	d := NewDense(Outside, g.Width(), g.Height())
+	g.Each(func(p grid.Point, r rune) {
+		d.Set(Coord{p.X, p.Y}, r)
+	})
+	return d
+}
+
+// Size is the length of each dimension.
+func (d *Dense) Size() []int { return d.size }
+
+// Dims is the number of dimensions.
+func (d *Dense) Dims() int { return len(d.size) }
+
+// index returns where c lives in cells, and false if c is out of bounds.
+func (d *Dense) index(c Coord) (int, bool) {
+	var (
+		i      int
+		stride = 1
+	)
+	for dim, s := range d.size {
+		if c[dim] < 0 || c[dim] >= s {
+			return 0, false
+		}
+		i += c[dim] * stride
+		stride *= s
+	}
+	for dim := len(d.size); dim < MaxDims; dim++ {
+		if c[dim] != 0 {
+			return 0, false
+		}
+	}
+	return i, true
+}
+
+// coord is the reverse of index.
+func (d *Dense) coord(i int) Coord {
+	var c Coord
+	for dim, s := range d.size {
+		c[dim] = i % s
+		i /= s
+	}
+	return c
+}
+
+// Get returns the cell at c, or Outside if c is out of bounds.
+func (d *Dense) Get(c Coord) rune {
+	i, ok := d.index(c)
+	if !ok {
+		return Outside
+	}
+	return d.cells[i]
+}
+
+// Set changes the cell at c. Setting a cell out of bounds does nothing.
+func (d *Dense) Set(c Coord, r rune) {
+	if i, ok := d.index(c); ok {
+		d.cells[i] = r
+	}
+}
+
+// Candidates calls fn for every cell.
+func (d *Dense) Candidates(_ Neighborhood, fn func(c Coord)) {
+	for i := range d.cells {
+		fn(d.coord(i))
+	}
+}
+
+// Empty returns a dense state of the same size.
+func (d *Dense) Empty() State {
+	return NewDense(Outside, d.size...)
+}
+
+// Key returns the cells as a string.
+func (d *Dense) Key() string { return string(d.cells) }
+
+// Count returns how many cells hold r.
+func (d *Dense) Count(r rune) int {
+	var n int
+	for _, c := range d.cells {
+		if c == r {
+			n++
+		}
+	}
+	return n
+}
+
+// String renders the state as rows of cells. States with more than two
+// dimensions are rendered as a series of 2D slices, each headed by its
+// coordinates in the higher dimensions.
+func (d *Dense) String() string {
+	width := d.size[0]
+	height := 1
+	if len(d.size) > 1 {
+		height = d.size[1]
+	}
+	var (
+		b     strings.Builder
+		slice = width * height
+	)
+	for start := 0; start < len(d.cells); start += slice {
+		if len(d.size) > 2 {
+			if start > 0 {
+				b.WriteString("\n\n")
+			}
+			c := d.coord(start)
+			fmt.Fprintf(&b, "%v\n", c[2:len(d.size)])
+		}
+		for y := 0; y < height; y++ {
+			if y > 0 {
+				b.WriteByte('\n')
+			}
+			row := start + y*width
+			b.WriteString(string(d.cells[row : row+width]))
+		}
+	}
+	return b.String()
+}
+
+// Values of the cells in a sparse state.
+const (
+	Alive rune = '#'
+	Dead  rune = '.'
+)
+
+// Sparse is an unbounded state where each cell is either Alive or Dead, and
+// only live cells are stored. Rules used with it must leave a dead cell
+// with no live neighbors dead.
+type Sparse struct {
+	dims int
+	live map[Coord]struct{}
+}
+
+// NewSparse returns a sparse state with no live cells.
+func NewSparse(dims int) *Sparse {
+	if dims < 1 || dims > MaxDims {
+		panic(fmt.Sprintf("automaton: sparse state must have 1 to %d dimensions, but got %d", MaxDims, dims))
+	}
+	return &Sparse{dims: dims, live: make(map[Coord]struct{})}
+}
+
+// SparseFromGrid returns a sparse state in dims dimensions whose live cells
+// are the cells of g holding alive, laid out in the plane where the third
+// and fourth coordinates are zero.
+func SparseFromGrid(g *grid.Grid, alive rune, dims int) *Sparse {
+	s := NewSparse(dims)
+	g.Each(func(p grid.Point, r rune) {
+		if r == alive {
+			s.Set(Coord{p.X, p.Y}, Alive)
+		}
+	})
+	return s
+}
+
+// Dims is the number of dimensions.
+func (s *Sparse) Dims() int { return s.dims }
+
+// Get returns Alive or Dead.
+func (s *Sparse) Get(c Coord) rune {
+	if _, ok := s.live[c]; ok {
+		return Alive
+	}
+	return Dead
+}
+
+// Set makes the cell at c alive if r is Alive, and dead otherwise.
+func (s *Sparse) Set(c Coord, r rune) {
+	if r == Alive {
+		s.live[c] = struct{}{}
+		return
+	}
+	delete(s.live, c)
+}
+
+// Candidates calls fn for every live cell and every neighbor of a live cell.
+func (s *Sparse) Candidates(nb Neighborhood, fn func(c Coord)) {
+	seen := make(map[Coord]struct{}, len(s.live)*3)
+	visit := func(c Coord) {
+		if _, ok := seen[c]; !ok {
+			seen[c] = struct{}{}
+			fn(c)
+		}
+	}
+	for c := range s.live {
+		visit(c)
+		for _, n := range nb(c) {
+			visit(n)
+		}
+	}
+}
+
+// Empty returns a sparse state with no live cells.
+func (s *Sparse) Empty() State { return NewSparse(s.dims) }
+
+// Len is the number of live cells.
+func (s *Sparse) Len() int { return len(s.live) }
+
+// Live returns the live cells in sorted order.
+func (s *Sparse) Live() []Coord {
+	coords := make([]Coord, 0, len(s.live))
+	for c := range s.live {
+		coords = append(coords, c)
+	}
+	sort.Slice(coords, func(i, j int) bool {
+		for d := range coords[i] {
+			if coords[i][d] != coords[j][d] {
+				return coords[i][d] < coords[j][d]
+			}
+		}
+		return false
+	})
+	return coords
+}
+
+// Key lists the live cells in sorted order.
+func (s *Sparse) Key() string {
+	var b strings.Builder
+	for _, c := range s.Live() {
+		fmt.Fprint(&b, c[:s.dims], ";")
+	}
+	return b.String()
+}
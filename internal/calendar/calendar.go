@@ -0,0 +1,140 @@
+// Package calendar knows when Advent of Code events run and when each of
+// their puzzles unlocks.
+package calendar
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// FirstYear is the year of the first event.
+	FirstYear = 2015
+
+	// ShortEventYear is the first year with a shorter event.
+	ShortEventYear = 2025
+)
+
+// Eastern is the time zone puzzles unlock in. Events only run in December,
+// so there's no daylight saving time to worry about, and a fixed zone avoids
+// depending on the system's time zone database.
+var Eastern = time.FixedZone("EST", -5*60*60)
+
+// Event is one year's Advent of Code.
+type Event struct {
+	Year int
+
+	// Days is the number of puzzles in the event.
+	Days int
+}
+
+// EventFor returns the event for a year, or an error if there was never an
+// event that year.
+func EventFor(year int) (Event, error) {
+	if year < FirstYear {
+		return Event{}, fmt.Errorf("there is no %d event: the first was in %d", year, FirstYear)
+	}
+	days := 25
+	if year >= ShortEventYear {
+		days = 12
+	}
+	return Event{Year: year, Days: days}, nil
+}
+
+// Validate checks that a puzzle exists, without regard to whether it has
+// been released yet.
+func Validate(year, day int) error {
+	e, err := EventFor(year)
+	if err != nil {
+		return err
+	}
+	return e.ValidateDay(day)
+}
+
+// ValidateDay checks that the event has a puzzle for the day.
+func (e Event) ValidateDay(day int) error {
+	if day < 1 || day > e.Days {
+		return fmt.Errorf("there is no day %d in %d: puzzles run from day 1 to day %d", day, e.Year, e.Days)
+	}
+	return nil
+}
+
+// Unlock returns the moment a day's puzzle is released. The day is not
+// validated.
+func (e Event) Unlock(day int) time.Time {
+	return time.Date(e.Year, time.December, day, 0, 0, 0, 0, Eastern)
+}
+
+// Start returns the moment the first puzzle is released.
+func (e Event) Start() time.Time {
+	return e.Unlock(1)
+}
+
+// End returns the moment the last puzzle has been out for a full day. The
+// site stays open after that, but nothing new happens.
+func (e Event) End() time.Time {
+	return e.Unlock(e.Days + 1)
+}
+
+// InProgress reports whether puzzles are still being released at now.
+func (e Event) InProgress(now time.Time) bool {
+	return !now.Before(e.Start()) && now.Before(e.End())
+}
+
+// Unlocked reports whether a day's puzzle has been released at now.
+func (e Event) Unlocked(day int, now time.Time) bool {
+	return !now.Before(e.Unlock(day))
+}
+
+// Latest returns the most recent event to have started at now. In January,
+// that is last year's event, not this year's.
+func Latest(now time.Time) (Event, error) {
+	year := now.In(Eastern).Year()
+	e, err := EventFor(year)
+	if err != nil {
+		return Event{}, err
+	}
+	if now.Before(e.Start()) {
+		return EventFor(year - 1)
+	}
+	return e, nil
+}
+
+// ValidateAt checks that a puzzle exists and that its event has at least been
+// announced at now. Puzzles later in an event that has started are fine,
+// since they can be waited for.
+func ValidateAt(year, day int, now time.Time) error {
+	if err := Validate(year, day); err != nil {
+		return err
+	}
+	if current := now.In(Eastern).Year(); year > current {
+		return fmt.Errorf("the %d event hasn't happened yet: it's only %d", year, current)
+	}
+	return nil
+}
+
+// Lead is how close to a puzzle's release the next puzzle is assumed to be
+// the one of interest.
+const Lead = time.Hour
+
+// LikelyDay picks the puzzle someone is most likely to be working on at now:
+// today's puzzle while an event is in progress, or the next day's if it
+// unlocks within the hour. The boolean result reports whether the next
+// day was chosen because of that. An error is returned outside of events,
+// when there's no good guess.
+func LikelyDay(now time.Time) (Event, int, bool, error) {
+	// Look ahead first, but fall back to today's puzzle if looking ahead
+	// runs off the end of the event.
+	for _, t := range []time.Time{now.Add(Lead), now} {
+		local := t.In(Eastern)
+		e, err := EventFor(local.Year())
+		if err != nil || !e.InProgress(t) {
+			continue
+		}
+		day := local.Day()
+		return e, day, !e.Unlocked(day, now), nil
+	}
+	return Event{}, 0, false, fmt.Errorf(
+		"no event is in progress at %s: specify a day",
+		now.In(Eastern).Format("2006-01-02 15:04 MST"))
+}
@@ -0,0 +1,108 @@
+package calendar
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func est(year int, month time.Month, day, hour, min int) time.Time {
+	return time.Date(year, month, day, hour, min, 0, 0, Eastern)
+}
+
+func TestValidate(t *testing.T) {
+	tt := []struct {
+		year, day int
+		valid     bool
+	}{
+		{2014, 1, false},
+		{2015, 1, true},
+		{2020, 25, true},
+		{2020, 26, false},
+		{2020, 0, false},
+		{2024, 25, true},
+		{2025, 12, true},
+		{2025, 13, false},
+	}
+	for _, tc := range tt {
+		t.Run(fmt.Sprintf("%d day %d", tc.year, tc.day), func(t *testing.T) {
+			err := Validate(tc.year, tc.day)
+			if valid := err == nil; valid != tc.valid {
+				t.Fatalf("expected valid=%t, but got error %v", tc.valid, err)
+			}
+		})
+	}
+}
+
+func TestLatest(t *testing.T) {
+	tt := []struct {
+		now  time.Time
+		want int
+	}{
+		{est(2021, time.January, 3, 12, 0), 2020},
+		{est(2020, time.November, 30, 23, 59), 2019},
+		{est(2020, time.December, 1, 0, 0), 2020},
+		{est(2020, time.December, 31, 12, 0), 2020},
+	}
+	for _, tc := range tt {
+		t.Run(tc.now.String(), func(t *testing.T) {
+			e, err := Latest(tc.now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if e.Year != tc.want {
+				t.Fatalf("expected %d, but got %d", tc.want, e.Year)
+			}
+		})
+	}
+}
+
+func TestLikelyDay(t *testing.T) {
+	tt := []struct {
+		now       time.Time
+		wantYear  int
+		wantDay   int
+		wantEarly bool
+		wantErr   bool
+	}{
+		{now: est(2020, time.December, 5, 12, 0), wantYear: 2020, wantDay: 5},
+		{now: est(2020, time.December, 5, 23, 15), wantYear: 2020, wantDay: 6, wantEarly: true},
+		{now: est(2020, time.November, 30, 23, 30), wantYear: 2020, wantDay: 1, wantEarly: true},
+		{now: est(2020, time.December, 25, 23, 30), wantYear: 2020, wantDay: 25},
+		{now: est(2025, time.December, 12, 23, 30), wantYear: 2025, wantDay: 12},
+		{now: est(2025, time.December, 13, 9, 0), wantErr: true},
+		{now: est(2021, time.January, 3, 12, 0), wantErr: true},
+		{now: est(2020, time.July, 4, 12, 0), wantErr: true},
+	}
+	for _, tc := range tt {
+		t.Run(tc.now.String(), func(t *testing.T) {
+			e, day, early, err := LikelyDay(tc.now)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, but got %d day %d", e.Year, day)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if e.Year != tc.wantYear || day != tc.wantDay || early != tc.wantEarly {
+				t.Fatalf(
+					"expected %d day %d (early=%t), but got %d day %d (early=%t)",
+					tc.wantYear, tc.wantDay, tc.wantEarly,
+					e.Year, day, early)
+			}
+		})
+	}
+}
+
+func TestUnlock(t *testing.T) {
+	e, _ := EventFor(2020)
+	want := time.Date(2020, time.December, 7, 5, 0, 0, 0, time.UTC)
+	if got := e.Unlock(7); !got.Equal(want) {
+		t.Fatalf("expected unlock at %v, but got %v", want, got)
+	}
+	if e.Unlocked(7, want.Add(-time.Second)) || !e.Unlocked(7, want) {
+		t.Fatal("expected day 7 to unlock exactly at midnight Eastern")
+	}
+}
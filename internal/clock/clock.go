@@ -0,0 +1,73 @@
+// Package clock abstracts the passage of time, so that code that waits for
+// puzzles to unlock can be tested without waiting.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells time and waits.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// Real is the wall clock.
+type Real struct{}
+
+// Now returns the current time.
+func (Real) Now() time.Time { return time.Now() }
+
+// After waits for the duration to elapse and then sends the current time on
+// the returned channel.
+func (Real) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Fake is a clock that only moves when told to. Waiting on it advances it
+// immediately by the requested duration, so code under test runs as fast as
+// it can while still seeing time pass.
+type Fake struct {
+	mu    sync.Mutex
+	now   time.Time
+	waits []time.Duration
+}
+
+// NewFake returns a Fake clock set to the given time.
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+// Now returns the fake current time.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// After advances the clock by d and returns a channel that has already
+// received the new time.
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if d > 0 {
+		f.now = f.now.Add(d)
+	}
+	f.waits = append(f.waits, d)
+	ch := make(chan time.Time, 1)
+	ch <- f.now
+	return ch
+}
+
+// Advance moves the clock forward without anyone waiting.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+// Waits returns the durations passed to After, in order.
+func (f *Fake) Waits() []time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]time.Duration(nil), f.waits...)
+}
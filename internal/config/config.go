@@ -0,0 +1,351 @@
+// Package config loads defaults for the admin tool from configuration files
+// and resolves them against environment variables and command-line flags.
+//
+// Two files are consulted, if they exist: a user-wide file in the XDG config
+// directory ($XDG_CONFIG_HOME/aoc/config.json, falling back to
+// ~/.config/aoc/config.json), and a repo-local override (.aoc.json in the
+// working directory). Each file holds any number of named profiles:
+//
+//	{
+//	  "default_profile": "home",
+//	  "profiles": {
+//	    "home": {
+//	      "token_source": "env:AOC_SESSION_TOKEN",
+//	      "leaderboard_ids": [123456],
+//	      "puzzle_root": "puzzles",
+//	      "template": "templates/puzzle",
+//	      "output_format": "text"
+//	    }
+//	  }
+//	}
+//
+// Settings are resolved in this order, with later sources winning: built-in
+// defaults, the user file, the repo-local file, environment variables, and
+// finally command-line flags.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/session"
+)
+
+const (
+	// RepoFileName is the name of the repo-local override file, which is
+	// looked up in the working directory.
+	RepoFileName = ".aoc.json"
+
+	// DefaultProfileName is used when neither a file nor the caller names a
+	// profile.
+	DefaultProfileName = "default"
+
+	// EnvVarProfile selects a profile by name.
+	EnvVarProfile = "AOC_PROFILE"
+
+	// TokenSourceSession names the token saved by the login command.
+	TokenSourceSession = "session"
+)
+
+// Sources of a setting, from lowest to highest precedence.
+const (
+	SourceDefault = "default"
+	SourceUser    = "user config"
+	SourceRepo    = "repo config"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+// Output formats understood by commands that print results.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+type (
+	// File is the on-disk representation of a configuration file.
+	File struct {
+		DefaultProfile string             `json:"default_profile,omitempty"`
+		Profiles       map[string]Profile `json:"profiles,omitempty"`
+	}
+
+	// Profile holds a named set of defaults. Empty fields are left to lower
+	// precedence sources.
+	Profile struct {
+		// TokenSource says where to find the session token: "session"
+		// uses the token saved by the login command, "env:NAME" reads an
+		// environment variable, "file:PATH" reads a file, and anything
+		// else is taken as the token itself.
+		TokenSource    string `json:"token_source,omitempty"`
+		LeaderboardIDs []uint `json:"leaderboard_ids,omitempty"`
+		PuzzleRoot     string `json:"puzzle_root,omitempty"`
+		Template       string `json:"template,omitempty"`
+		OutputFormat   string `json:"output_format,omitempty"`
+	}
+)
+
+// Setting is a single resolved value along with where it came from.
+type Setting struct {
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// Settings are the fully resolved defaults for the admin tool.
+type Settings struct {
+	Profile        Setting
+	TokenSource    Setting
+	LeaderboardIDs Setting
+	PuzzleRoot     Setting
+	Template       Setting
+	OutputFormat   Setting
+
+	// Files lists the configuration files that were found and read.
+	Files []string
+}
+
+// Defaults returns the built-in settings used when nothing else is
+// configured.
+func Defaults() Settings {
+	return Settings{
+		Profile:        Setting{DefaultProfileName, SourceDefault},
+		TokenSource:    Setting{TokenSourceSession, SourceDefault},
+		LeaderboardIDs: Setting{"", SourceDefault},
+		PuzzleRoot:     Setting{"puzzles", SourceDefault},
+		Template:       Setting{filepath.Join("templates", "puzzle"), SourceDefault},
+		OutputFormat:   Setting{FormatText, SourceDefault},
+	}
+}
+
+// UserFilePath returns the location of the user-wide configuration file.
+func UserFilePath() (string, error) {
+	dir, err := UserDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+// UserDir returns the directory holding user-wide admin tool state, honoring
+// XDG_CONFIG_HOME.
+func UserDir() (string, error) {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "aoc"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("locating config directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "aoc"), nil
+}
+
+// SessionFilePath returns the location of the token saved by the login
+// command.
+func SessionFilePath() (string, error) {
+	dir, err := UserDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "session.json"), nil
+}
+
+// ReadFile decodes a configuration file. A missing file is not an error: it
+// yields a nil *File.
+func ReadFile(path string) (*File, error) {
+	b, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f File
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("decoding config file %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+// Load reads the user and repo-local configuration files and resolves the
+// named profile. If profile is empty, AOC_PROFILE and then the files'
+// default_profile are consulted. Callers apply environment variables and
+// flags on top of the result with Setting.Override.
+func Load(profile string) (Settings, error) {
+	userPath, err := UserFilePath()
+	if err != nil {
+		return Settings{}, err
+	}
+	user, err := ReadFile(userPath)
+	if err != nil {
+		return Settings{}, err
+	}
+	repo, err := ReadFile(RepoFileName)
+	if err != nil {
+		return Settings{}, err
+	}
+
+	selected := Setting{profile, SourceFlag}
+	if profile == "" {
+		selected = Setting{os.Getenv(EnvVarProfile), SourceEnv}
+	}
+	s, err := Resolve(selected, user, repo)
+	if err != nil {
+		return Settings{}, err
+	}
+	if user != nil {
+		s.Files = append(s.Files, userPath)
+	}
+	if repo != nil {
+		s.Files = append(s.Files, RepoFileName)
+	}
+	return s, nil
+}
+
+// Resolve layers the built-in defaults, the user file, and the repo-local
+// file, in that order, for the selected profile. Either file may be nil. If
+// the profile setting is empty, the files' default_profile is used.
+func Resolve(profile Setting, user, repo *File) (Settings, error) {
+	s := Defaults()
+
+	switch {
+	case profile.Value != "":
+		s.Profile = profile
+	case repo != nil && repo.DefaultProfile != "":
+		s.Profile = Setting{repo.DefaultProfile, SourceRepo}
+	case user != nil && user.DefaultProfile != "":
+		s.Profile = Setting{user.DefaultProfile, SourceUser}
+	}
+
+	var found bool
+	for _, layer := range []struct {
+		file   *File
+		source string
+	}{
+		{user, SourceUser},
+		{repo, SourceRepo},
+	} {
+		if layer.file == nil {
+			continue
+		}
+		p, ok := layer.file.Profiles[s.Profile.Value]
+		if !ok {
+			continue
+		}
+		found = true
+		s.apply(p, layer.source)
+	}
+
+	// Asking for a profile that doesn't exist is almost certainly a typo, but
+	// running with no configuration at all is fine.
+	if !found && s.Profile.Source != SourceDefault {
+		return Settings{}, fmt.Errorf("profile %q not found in any config file", s.Profile.Value)
+	}
+
+	if err := s.Validate(); err != nil {
+		return Settings{}, err
+	}
+	return s, nil
+}
+
+func (s *Settings) apply(p Profile, source string) {
+	set := func(dst *Setting, v string) {
+		if v != "" {
+			*dst = Setting{v, source}
+		}
+	}
+	set(&s.TokenSource, p.TokenSource)
+	set(&s.PuzzleRoot, p.PuzzleRoot)
+	set(&s.Template, p.Template)
+	set(&s.OutputFormat, p.OutputFormat)
+	if len(p.LeaderboardIDs) > 0 {
+		ids := make([]string, 0, len(p.LeaderboardIDs))
+		for _, id := range p.LeaderboardIDs {
+			ids = append(ids, strconv.FormatUint(uint64(id), 10))
+		}
+		s.LeaderboardIDs = Setting{strings.Join(ids, ","), source}
+	}
+}
+
+// Override replaces a setting with a value from the environment or a flag.
+// Empty values are ignored.
+func (s *Setting) Override(value, source string) {
+	if value == "" {
+		return
+	}
+	*s = Setting{value, source}
+}
+
+// Validate checks that settings hold sensible values. It should be called
+// again after applying overrides.
+func (s Settings) Validate() error {
+	switch s.OutputFormat.Value {
+	case FormatText, FormatJSON:
+	default:
+		return fmt.Errorf(
+			"invalid output format %q (%s): must be %q or %q",
+			s.OutputFormat.Value, s.OutputFormat.Source, FormatText, FormatJSON)
+	}
+	if _, err := s.Leaderboards(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Leaderboards parses the resolved leaderboard IDs.
+func (s Settings) Leaderboards() ([]uint, error) {
+	if s.LeaderboardIDs.Value == "" {
+		return nil, nil
+	}
+	var ids []uint
+	for _, field := range strings.Split(s.LeaderboardIDs.Value, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(field), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"invalid leaderboard ID %q (%s): %w",
+				field, s.LeaderboardIDs.Source, err)
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, nil
+}
+
+// Token resolves the configured token source into a session token. An empty
+// token with no error means no token is configured.
+func (s Settings) Token() (string, error) {
+	src := s.TokenSource.Value
+	switch {
+	case src == TokenSourceSession:
+		path, err := SessionFilePath()
+		if err != nil {
+			return "", err
+		}
+		stored, err := session.Load(path)
+		if err != nil {
+			return "", err
+		}
+		return stored.Token, nil
+	case strings.HasPrefix(src, "env:"):
+		return os.Getenv(strings.TrimPrefix(src, "env:")), nil
+	case strings.HasPrefix(src, "file:"):
+		path := strings.TrimPrefix(src, "file:")
+		if strings.HasPrefix(path, "~/") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+			path = filepath.Join(home, path[2:])
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("reading token file: %w", err)
+		}
+		return strings.TrimSpace(string(b)), nil
+	default:
+		return src, nil
+	}
+}
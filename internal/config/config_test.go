@@ -0,0 +1,86 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	user := &File{
+		DefaultProfile: "home",
+		Profiles: map[string]Profile{
+			"home": {
+				LeaderboardIDs: []uint{1, 2},
+				PuzzleRoot:     "user-puzzles",
+				OutputFormat:   FormatJSON,
+			},
+			"work": {
+				TokenSource: "file:/tmp/token",
+			},
+		},
+	}
+	repo := &File{
+		Profiles: map[string]Profile{
+			"home": {
+				PuzzleRoot: "repo-puzzles",
+			},
+		},
+	}
+
+	tt := []struct {
+		name    string
+		profile Setting
+		user    *File
+		repo    *File
+		want    Settings
+	}{
+		{
+			name: "no files",
+			want: Defaults(),
+		},
+		{
+			name: "repo overrides user",
+			user: user,
+			repo: repo,
+			want: Settings{
+				Profile:        Setting{"home", SourceUser},
+				TokenSource:    Defaults().TokenSource,
+				LeaderboardIDs: Setting{"1,2", SourceUser},
+				PuzzleRoot:     Setting{"repo-puzzles", SourceRepo},
+				Template:       Defaults().Template,
+				OutputFormat:   Setting{FormatJSON, SourceUser},
+			},
+		},
+		{
+			name:    "named profile",
+			profile: Setting{"work", SourceFlag},
+			user:    user,
+			repo:    repo,
+			want: Settings{
+				Profile:        Setting{"work", SourceFlag},
+				TokenSource:    Setting{"file:/tmp/token", SourceUser},
+				LeaderboardIDs: Defaults().LeaderboardIDs,
+				PuzzleRoot:     Defaults().PuzzleRoot,
+				Template:       Defaults().Template,
+				OutputFormat:   Defaults().OutputFormat,
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Resolve(tc.profile, tc.user, tc.repo)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("expected %+v, but got %+v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestResolveUnknownProfile(t *testing.T) {
+	if _, err := Resolve(Setting{"nope", SourceFlag}, &File{}, nil); err == nil {
+		t.Fatal("expected error for unknown profile")
+	}
+}
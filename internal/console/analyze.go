@@ -0,0 +1,255 @@
+package console
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Fix is a patch that makes a program halt, and the accumulator it halts
+// with.
+type Fix struct {
+	Patch
+	Acc int
+}
+
+// Analysis is what can be worked out about a program without running it more
+// than once, going by the default operations.
+type Analysis struct {
+	Program Program
+
+	// Outcome is how the program ends when it runs as it is.
+	Outcome Outcome
+
+	// Reachable reports whether each instruction runs when the program does.
+	Reachable []bool
+
+	// Loops are the cycles in the program, whether or not it reaches them.
+	// Each lists its instructions in the order they would run, starting with
+	// the lowest.
+	Loops [][]int
+
+	// OutOfRange are the instructions that move the program counter outside
+	// the program, other than to just past its end.
+	OutOfRange []int
+
+	// Fixes are the swaps of a jmp for a nop, or a nop for a jmp, that make
+	// a program that doesn't halt do so.
+	Fixes []Fix
+}
+
+// Analyze works out the control flow of prog, and every single swap that
+// would make it halt. It takes time in proportion to the length of prog.
+func Analyze(prog Program) (*Analysis, error) {
+	a := &Analysis{Program: prog, Reachable: make([]bool, len(prog))}
+
+	// Run the program without a machine, to note the accumulator before each
+	// instruction. Fixes need it.
+	accBefore := make([]int, len(prog))
+	var acc int
+	for pc := 0; ; {
+		if pc == len(prog) {
+			a.Outcome = Halted
+			break
+		}
+		if pc < 0 || pc > len(prog) {
+			a.Outcome = OutOfBounds
+			break
+		}
+		if a.Reachable[pc] {
+			a.Outcome = Looped
+			break
+		}
+		a.Reachable[pc] = true
+		accBefore[pc] = acc
+		if prog[pc].Op == "acc" {
+			acc += prog[pc].Arg
+		}
+		pc = Next(prog[pc], pc)
+	}
+
+	for pc, in := range prog {
+		if next := Next(in, pc); next < 0 || next > len(prog) {
+			a.OutOfRange = append(a.OutOfRange, pc)
+		}
+	}
+	a.Loops = loops(prog)
+
+	patches, err := Repairs(prog)
+	if err != nil && !errors.Is(err, ErrHalts) {
+		return nil, err
+	}
+	if len(patches) == 0 {
+		return a, nil
+	}
+
+	// A fixed program runs as before up to the swapped instruction, and then
+	// as the original would from wherever the swap sends it, so the
+	// accumulator it halts with is the sum of the two parts.
+	_, order := terminating(prog)
+	accToEnd := make([]int, len(prog)+1)
+	for _, pc := range order[1:] {
+		accToEnd[pc] = accToEnd[Next(prog[pc], pc)]
+		if prog[pc].Op == "acc" {
+			accToEnd[pc] += prog[pc].Arg
+		}
+	}
+	for _, p := range patches {
+		a.Fixes = append(a.Fixes, Fix{Patch: p, Acc: accBefore[p.PC] + accToEnd[Next(p.To, p.PC)]})
+	}
+	return a, nil
+}
+
+// loops finds the cycles in prog. Every instruction goes to exactly one
+// place, so following each one until it reaches somewhere it's already been
+// finds every cycle in a single pass.
+func loops(prog Program) [][]int {
+	const (
+		unseen = iota
+		walking
+		done
+	)
+	var (
+		state = make([]int, len(prog))
+		found [][]int
+	)
+	for start := range prog {
+		var walk []int
+		pc := start
+		for pc >= 0 && pc < len(prog) && state[pc] == unseen {
+			state[pc] = walking
+			walk = append(walk, pc)
+			pc = Next(prog[pc], pc)
+		}
+		if pc >= 0 && pc < len(prog) && state[pc] == walking {
+			for i, w := range walk {
+				if w == pc {
+					found = append(found, rotate(walk[i:]))
+					break
+				}
+			}
+		}
+		for _, w := range walk {
+			state[w] = done
+		}
+	}
+	return found
+}
+
+// rotate returns the cycle starting with its lowest instruction.
+func rotate(cycle []int) []int {
+	low := 0
+	for i, pc := range cycle {
+		if pc < cycle[low] {
+			low = i
+		}
+	}
+	return append(append([]int{}, cycle[low:]...), cycle[:low]...)
+}
+
+// Unreachable returns the instructions that never run.
+func (a *Analysis) Unreachable() []int {
+	var pcs []int
+	for pc, ok := range a.Reachable {
+		if !ok {
+			pcs = append(pcs, pc)
+		}
+	}
+	return pcs
+}
+
+// WriteReport writes the analysis as text.
+func (a *Analysis) WriteReport(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	describe := func(pc int) string {
+		return fmt.Sprintf("%6d  %-10v ; line %d", pc, a.Program[pc], a.Program[pc].Line)
+	}
+
+	fmt.Fprintf(bw, "%d instructions, %v when run\n", len(a.Program), a.Outcome)
+
+	unreachable := a.Unreachable()
+	fmt.Fprintf(bw, "\nunreachable: %d\n", len(unreachable))
+	for _, pc := range unreachable {
+		fmt.Fprintln(bw, describe(pc))
+	}
+
+	fmt.Fprintf(bw, "\nloops: %d\n", len(a.Loops))
+	for _, loop := range a.Loops {
+		pcs := make([]string, len(loop))
+		for i, pc := range loop {
+			pcs[i] = fmt.Sprint(pc)
+		}
+		reached := ""
+		if a.Reachable[loop[0]] {
+			reached = " (reached)"
+		}
+		fmt.Fprintf(bw, "  %s -> %d%s\n", strings.Join(pcs, " -> "), loop[0], reached)
+	}
+
+	fmt.Fprintf(bw, "\nout of range: %d\n", len(a.OutOfRange))
+	for _, pc := range a.OutOfRange {
+		fmt.Fprintf(bw, "%s -> %d\n", describe(pc), Next(a.Program[pc], pc))
+	}
+
+	fmt.Fprintf(bw, "\nfixes: %d\n", len(a.Fixes))
+	for _, f := range a.Fixes {
+		fmt.Fprintf(bw, "  %v, acc=%d\n", f.Patch, f.Acc)
+	}
+	return bw.Flush()
+}
+
+// WriteDOT writes the control flow graph in Graphviz's DOT language.
+// Unreachable instructions are dashed, loops are red, jumps out of range go
+// to a node of their own, and fixes are green dashed edges labeled with the
+// accumulator they halt with.
+func (a *Analysis) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	inLoop := make(map[int]bool)
+	for _, loop := range a.Loops {
+		for _, pc := range loop {
+			inLoop[pc] = true
+		}
+	}
+	node := func(pc int) string {
+		if pc == len(a.Program) {
+			return "end"
+		}
+		return fmt.Sprintf("n%d", pc)
+	}
+
+	fmt.Fprintf(bw, "digraph program {\n")
+	fmt.Fprintf(bw, "\tnode [shape=box, fontname=monospace];\n")
+	fmt.Fprintf(bw, "\tstart [shape=point];\n")
+	fmt.Fprintf(bw, "\tend [shape=doublecircle, label=\"end\"];\n")
+	if len(a.Program) > 0 {
+		fmt.Fprintf(bw, "\tstart -> n0;\n")
+	} else {
+		fmt.Fprintf(bw, "\tstart -> end;\n")
+	}
+	for pc, in := range a.Program {
+		attrs := []string{fmt.Sprintf("label=\"%d: %v\"", pc, in)}
+		if !a.Reachable[pc] {
+			attrs = append(attrs, "style=dashed", "fontcolor=gray")
+		}
+		if inLoop[pc] {
+			attrs = append(attrs, "color=red")
+		}
+		fmt.Fprintf(bw, "\t%s [%s];\n", node(pc), strings.Join(attrs, ", "))
+
+		next := Next(in, pc)
+		if next < 0 || next > len(a.Program) {
+			fmt.Fprintf(bw, "\tout%d [shape=plaintext, fontcolor=red, label=\"%d\"];\n", pc, next)
+			fmt.Fprintf(bw, "\t%s -> out%d [color=red];\n", node(pc), pc)
+			continue
+		}
+		fmt.Fprintf(bw, "\t%s -> %s;\n", node(pc), node(next))
+	}
+	for _, f := range a.Fixes {
+		fmt.Fprintf(bw, "\t%s -> %s [style=dashed, color=darkgreen, label=\"%v: acc=%d\"];\n",
+			node(f.PC), node(Next(f.To, f.PC)), f.To, f.Acc)
+	}
+	fmt.Fprintf(bw, "}\n")
+	return bw.Flush()
+}
@@ -0,0 +1,116 @@
+package console
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	tt := []struct {
+		name        string
+		src         string
+		outcome     Outcome
+		unreachable []int
+		loops       [][]int
+		outOfRange  []int
+		fixes       []string
+	}{
+		{
+			name:        "sample",
+			src:         sample,
+			outcome:     Looped,
+			unreachable: []int{5, 8},
+			loops:       [][]int{{1, 2, 6, 7, 3, 4}},
+			fixes:       []string{"line 8: jmp -4 -> nop -4, acc=8"},
+		},
+		{
+			name:        "unreached loop",
+			src:         "acc +1\njmp +3\njmp +0\nacc +2\nacc +3",
+			outcome:     Halted,
+			unreachable: []int{2, 3},
+			loops:       [][]int{{2}},
+		},
+		{
+			name:        "out of range",
+			src:         "acc +4\nnop +2\njmp +7\nacc +1",
+			outcome:     OutOfBounds,
+			unreachable: []int{3},
+			outOfRange:  []int{2},
+			fixes: []string{
+				"line 2: nop +2 -> jmp +2, acc=5",
+				"line 3: jmp +7 -> nop +7, acc=5",
+			},
+		},
+		{
+			name:        "two fixes",
+			src:         "acc +1\nnop +3\nacc +2\njmp -2\nacc +4",
+			outcome:     Looped,
+			unreachable: []int{4},
+			loops:       [][]int{{1, 2, 3}},
+			fixes: []string{
+				"line 2: nop +3 -> jmp +3, acc=5",
+				"line 4: jmp -2 -> nop -2, acc=7",
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			prog := mustParse(t, tc.src)
+			a, err := Analyze(prog)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if a.Outcome != tc.outcome {
+				t.Errorf("expected %v, but got %v", tc.outcome, a.Outcome)
+			}
+			if got := a.Unreachable(); !reflect.DeepEqual(got, tc.unreachable) {
+				t.Errorf("expected unreachable %v, but got %v", tc.unreachable, got)
+			}
+			if !reflect.DeepEqual(a.Loops, tc.loops) {
+				t.Errorf("expected loops %v, but got %v", tc.loops, a.Loops)
+			}
+			if !reflect.DeepEqual(a.OutOfRange, tc.outOfRange) {
+				t.Errorf("expected out of range %v, but got %v", tc.outOfRange, a.OutOfRange)
+			}
+
+			var fixes []string
+			for _, f := range a.Fixes {
+				fixes = append(fixes, fmt.Sprintf("%v, acc=%d", f.Patch, f.Acc))
+				res, err := Run(f.Apply(prog))
+				if err != nil || res.Outcome != Halted || res.State.Acc != f.Acc {
+					t.Errorf("%v: expected to halt with acc=%d, but got %v with acc=%d (%v)",
+						f.Patch, f.Acc, res.Outcome, res.State.Acc, err)
+				}
+			}
+			if !reflect.DeepEqual(fixes, tc.fixes) {
+				t.Errorf("expected fixes %q, but got %q", tc.fixes, fixes)
+			}
+		})
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	a, err := Analyze(mustParse(t, "jmp +2\njmp +0\njmp +9\nacc +1"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var out bytes.Buffer
+	if err := a.WriteDOT(&out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"start -> n0;",
+		"n2 -> out2 [color=red];",
+		`n1 [label="1: jmp +0", style=dashed, fontcolor=gray, color=red];`,
+		"n1 -> n1;",
+		"n3 -> end;",
+		`n2 -> n3 [style=dashed, color=darkgreen, label="nop +9: acc=1"];`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected output to contain %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Package console emulates the handheld game console from day 8: a program
+// of instructions, each an operation and a signed argument, run against an
+// accumulator and a program counter.
+//
+// The operations are pluggable, so puzzles that add to the instruction set
+// can bring their own alongside nop, acc and jmp.
+package console
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/input"
+)
+
+// Instruction is one line of a program.
+type Instruction struct {
+	Op  string
+	Arg int
+
+	// Line is the line of the source the instruction came from, counting
+	// from 1, or 0 if it didn't come from source.
+	Line int
+}
+
+func (in Instruction) String() string {
+	return fmt.Sprintf("%s %+d", in.Op, in.Arg)
+}
+
+// Program is a list of instructions.
+type Program []Instruction
+
+// Parse reads a program, one instruction per line. Blank lines are skipped,
+// but still count towards line numbers.
+func Parse(lines []string) (Program, error) {
+	prog := make(Program, 0, len(lines))
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		in, err := ParseInstruction(line)
+		if err != nil {
+			return nil, &input.Error{Line: i + 1, Err: err}
+		}
+		in.Line = i + 1
+		prog = append(prog, in)
+	}
+	return prog, nil
+}
+
+// ParseInstruction reads an instruction like "jmp -4".
+func ParseInstruction(s string) (Instruction, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return Instruction{}, fmt.Errorf("invalid instruction %q", s)
+	}
+	arg, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return Instruction{}, fmt.Errorf("invalid argument in %q: %w", s, errors.Unwrap(err))
+	}
+	return Instruction{Op: fields[0], Arg: arg}, nil
+}
+
+// Clone returns a copy of the program that can be changed without changing
+// the original.
+func (p Program) Clone() Program {
+	return append(Program(nil), p...)
+}
+
+// String lists the program, one instruction per line.
+func (p Program) String() string {
+	var b strings.Builder
+	for _, in := range p {
+		fmt.Fprintln(&b, in)
+	}
+	return b.String()
+}
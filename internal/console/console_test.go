@@ -0,0 +1,121 @@
+package console
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/input"
+)
+
+const sample = `nop +0
+acc +1
+jmp +4
+acc +3
+jmp -3
+acc -99
+acc +1
+jmp -4
+acc +6`
+
+func mustParse(t *testing.T, src string) Program {
+	t.Helper()
+	prog, err := Parse(strings.Split(src, "\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return prog
+}
+
+func TestParse(t *testing.T) {
+	prog := mustParse(t, "nop +0\n\njmp -4")
+	if len(prog) != 2 || prog[1].Line != 3 || prog[1].Arg != -4 || prog[1].String() != "jmp -4" {
+		t.Fatalf("unexpected program: %#v", prog)
+	}
+
+	tt := []struct {
+		src  string
+		line int
+	}{
+		{src: "nop +0\njmp", line: 2},
+		{src: "acc +x", line: 1},
+	}
+	for _, tc := range tt {
+		_, err := Parse(strings.Split(tc.src, "\n"))
+		var ie *input.Error
+		if !errors.As(err, &ie) || ie.Line != tc.line {
+			t.Errorf("%q: expected an error on line %d, but got %v", tc.src, tc.line, err)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	tt := []struct {
+		name    string
+		src     string
+		outcome Outcome
+		acc, pc int
+	}{
+		{name: "loop", src: sample, outcome: Looped, acc: 5, pc: 1},
+		{name: "halt", src: strings.Replace(sample, "jmp -4", "nop -4", 1), outcome: Halted, acc: 8, pc: 9},
+		{name: "out of bounds", src: "acc +2\njmp +5", outcome: OutOfBounds, acc: 2, pc: 6},
+		{name: "before start", src: "jmp -1", outcome: OutOfBounds, pc: -1},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := Run(mustParse(t, tc.src))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Outcome != tc.outcome || res.State.Acc != tc.acc || res.State.PC != tc.pc {
+				t.Fatalf("expected %v with acc=%d pc=%d, but got %v with acc=%d pc=%d",
+					tc.outcome, tc.acc, tc.pc, res.Outcome, res.State.Acc, res.State.PC)
+			}
+		})
+	}
+}
+
+func TestOps(t *testing.T) {
+	m := New(mustParse(t, "mul +3\nacc +2\nmul -1"))
+	if _, err := m.Run(); !errors.Is(err, ErrUnknownOp) {
+		t.Fatalf("expected %v, but got %v", ErrUnknownOp, err)
+	}
+
+	m.Reset()
+	m.Ops["mul"] = func(s *State, arg int) { s.Acc *= arg; s.PC++ }
+	var steps []Step
+	m.Trace = func(s Step) { steps = append(steps, s) }
+	res, err := m.Run()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Outcome != Halted || res.State.Acc != -2 {
+		t.Fatalf("expected to halt with acc=-2, but got %v with acc=%d", res.Outcome, res.State.Acc)
+	}
+	if len(steps) != 3 || steps[1].Before.Acc != 0 || steps[1].After.Acc != 2 {
+		t.Fatalf("unexpected trace: %+v", steps)
+	}
+}
+
+func TestDebugger(t *testing.T) {
+	d := NewDebugger(New(mustParse(t, sample)))
+	var out strings.Builder
+	script := "b 4\nc\nregs\nc\n\nl 1\nrestart\nw\nc\nq\nstep\n"
+	if err := d.Run(strings.NewReader(script), &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"breakpoint: at 4 (line 5): jmp -3, acc=5",
+		"pc=4 acc=5 steps=6 running; breakpoints [4]; watching acc: false",
+		"program looped: pc=1 acc=5 after 7 steps",
+		"=>     1 .  acc +1",
+		"acc changed from 0 to 1",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected output to contain %q:\n%s", want, out.String())
+		}
+	}
+	if d.M.Steps != 2 {
+		t.Errorf("expected quit to stop the script, but ran %d steps", d.M.Steps)
+	}
+}
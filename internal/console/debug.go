@@ -0,0 +1,244 @@
+package console
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Debugger steps through a program on a machine interactively.
+type Debugger struct {
+	M *Machine
+
+	// Breakpoints are the instructions, by index, that stop continue before
+	// they run.
+	Breakpoints map[int]bool
+
+	// WatchAcc stops continue whenever the accumulator changes.
+	WatchAcc bool
+
+	out io.Writer
+}
+
+// NewDebugger returns a debugger for m.
+func NewDebugger(m *Machine) *Debugger {
+	return &Debugger{M: m, Breakpoints: make(map[int]bool), out: ioutil.Discard}
+}
+
+const debugHelp = `commands:
+  s, step [n]      run the next n instructions (default 1)
+  c, continue      run until a breakpoint, a change to acc if watching, or the end
+  b, break n       stop before instruction n
+  d, delete [n]    remove the breakpoint at n, or all of them
+  w, watch         toggle stopping when acc changes
+  l, list [n]      show the instructions within n of the current one (default 5)
+  r, regs          show the registers
+  restart          start the program again
+  h, help          show this help
+  q, quit          stop debugging
+An empty line repeats the last command.`
+
+// Run reads commands from r and writes what happens to w, until r runs out
+// or the quit command.
+func (d *Debugger) Run(r io.Reader, w io.Writer) error {
+	d.out = w
+	var (
+		s    = bufio.NewScanner(r)
+		last string
+	)
+	d.printf("%d instructions; type help for commands\n", len(d.M.Program))
+	for {
+		d.printf("(console) ")
+		if !s.Scan() {
+			d.printf("\n")
+			return s.Err()
+		}
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			line = last
+		}
+		last = line
+		if line == "" {
+			continue
+		}
+		quit, err := d.Exec(line)
+		if err != nil {
+			d.printf("error: %v\n", err)
+		}
+		if quit {
+			return nil
+		}
+	}
+}
+
+// Exec runs one debugger command, and reports whether it was quit.
+func (d *Debugger) Exec(line string) (bool, error) {
+	var (
+		fields = strings.Fields(line)
+		cmd    = fields[0]
+		args   = fields[1:]
+	)
+	n, err := intArg(args)
+	if err != nil {
+		return false, err
+	}
+	switch cmd {
+	case "s", "step":
+		if n == nil {
+			n = intPtr(1)
+		}
+		return false, d.step(*n)
+	case "c", "continue":
+		return false, d.cont()
+	case "b", "break":
+		if n == nil {
+			return false, fmt.Errorf("break needs an instruction number")
+		}
+		if *n < 0 || *n >= len(d.M.Program) {
+			return false, fmt.Errorf("no instruction %d", *n)
+		}
+		d.Breakpoints[*n] = true
+		d.printf("breakpoint at %s\n", d.describe(*n))
+	case "d", "delete":
+		if n == nil {
+			d.Breakpoints = make(map[int]bool)
+			d.printf("deleted all breakpoints\n")
+		} else {
+			delete(d.Breakpoints, *n)
+			d.printf("deleted breakpoint at %d\n", *n)
+		}
+	case "w", "watch":
+		d.WatchAcc = !d.WatchAcc
+		d.printf("watching acc: %t\n", d.WatchAcc)
+	case "l", "list":
+		if n == nil {
+			n = intPtr(5)
+		}
+		d.list(*n)
+	case "r", "regs":
+		d.regs()
+	case "restart":
+		d.M.Reset()
+		d.regs()
+	case "h", "help":
+		d.printf("%s\n", debugHelp)
+	case "q", "quit":
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown command %q; type help for commands", cmd)
+	}
+	return false, nil
+}
+
+func (d *Debugger) step(n int) error {
+	for i := 0; i < n; i++ {
+		done, err := d.next()
+		if err != nil || done {
+			return err
+		}
+	}
+	d.where()
+	return nil
+}
+
+func (d *Debugger) cont() error {
+	for {
+		done, err := d.next()
+		if err != nil || done {
+			return err
+		}
+		if pc := d.M.State.PC; d.Breakpoints[pc] {
+			d.printf("breakpoint: ")
+			d.where()
+			return nil
+		}
+	}
+}
+
+// next runs one instruction, and reports whether the debugger should stop:
+// because the program has ended, or because the watched acc changed.
+func (d *Debugger) next() (bool, error) {
+	before := d.M.State.Acc
+	o, err := d.M.Step()
+	if err != nil {
+		return true, err
+	}
+	if o != Running {
+		d.printf("program %v: pc=%d acc=%d after %d steps\n", o, d.M.State.PC, d.M.State.Acc, d.M.Steps)
+		return true, nil
+	}
+	if d.WatchAcc && d.M.State.Acc != before {
+		d.printf("acc changed from %d to %d\n", before, d.M.State.Acc)
+		d.where()
+		return true, nil
+	}
+	return false, nil
+}
+
+func (d *Debugger) where() {
+	d.printf("at %s, acc=%d\n", d.describe(d.M.State.PC), d.M.State.Acc)
+}
+
+func (d *Debugger) describe(pc int) string {
+	if pc < 0 || pc >= len(d.M.Program) {
+		return fmt.Sprintf("%d (outside the program)", pc)
+	}
+	in := d.M.Program[pc]
+	return fmt.Sprintf("%d (line %d): %v", pc, in.Line, in)
+}
+
+// list disassembles the instructions around the current one. The current
+// instruction is marked with an arrow, breakpoints with an asterisk, and
+// instructions that have already run with a dot.
+func (d *Debugger) list(n int) {
+	pc := d.M.State.PC
+	for i := pc - n; i <= pc+n; i++ {
+		if i < 0 || i >= len(d.M.Program) {
+			continue
+		}
+		mark := "  "
+		if i == pc {
+			mark = "=>"
+		}
+		flags := ""
+		if d.Breakpoints[i] {
+			flags += "*"
+		}
+		if d.M.Visited(i) {
+			flags += "."
+		}
+		in := d.M.Program[i]
+		d.printf("%s %5d %-2s %-8v ; line %d\n", mark, i, flags, in, in.Line)
+	}
+}
+
+func (d *Debugger) regs() {
+	var bps []int
+	for pc := range d.Breakpoints {
+		bps = append(bps, pc)
+	}
+	sort.Ints(bps)
+	d.printf("pc=%d acc=%d steps=%d %v; breakpoints %v; watching acc: %t\n",
+		d.M.State.PC, d.M.State.Acc, d.M.Steps, d.M.Outcome(), bps, d.WatchAcc)
+}
+
+func (d *Debugger) printf(format string, params ...interface{}) {
+	fmt.Fprintf(d.out, format, params...)
+}
+
+func intArg(args []string) (*int, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %q", args[0])
+	}
+	return &n, nil
+}
+
+func intPtr(n int) *int { return &n }
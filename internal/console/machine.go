@@ -0,0 +1,189 @@
+package console
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
+)
+
+// State is the registers of the machine.
+type State struct {
+	// PC is the index of the next instruction to run.
+	PC int
+
+	// Acc is the accumulator.
+	Acc int
+}
+
+// Op carries out an operation with its argument, changing the state. Ops
+// that don't jump must move the program counter on themselves.
+type Op func(s *State, arg int)
+
+// Ops are the operations the console knows about.
+type Ops map[string]Op
+
+// DefaultOps returns the instruction set from day 8.
+func DefaultOps() Ops {
+	return Ops{
+		"nop": func(s *State, arg int) { s.PC++ },
+		"acc": func(s *State, arg int) { s.Acc += arg; s.PC++ },
+		"jmp": func(s *State, arg int) { s.PC += arg },
+	}
+}
+
+// ErrUnknownOp is returned for an instruction that has no Op.
+var ErrUnknownOp = errors.New("unknown operation")
+
+// Outcome is how a run of a program ended.
+type Outcome int
+
+const (
+	// Running means the program hasn't finished yet.
+	Running Outcome = iota
+
+	// Halted means the program counter moved to just past the last
+	// instruction, which is how a program ends normally.
+	Halted
+
+	// Looped means an instruction was about to run for the second time,
+	// which means the program would run forever.
+	Looped
+
+	// OutOfBounds means the program counter moved anywhere else outside the
+	// program.
+	OutOfBounds
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case Running:
+		return "running"
+	case Halted:
+		return "halted"
+	case Looped:
+		return "looped"
+	case OutOfBounds:
+		return "out of bounds"
+	}
+	return fmt.Sprintf("Outcome(%d)", int(o))
+}
+
+// Result is the end of a run.
+type Result struct {
+	Outcome Outcome
+
+	// State is the registers when the program ended. For a loop, it's the
+	// state just before the repeated instruction would run again.
+	State State
+
+	// Steps is how many instructions ran.
+	Steps int
+}
+
+// Step is one instruction run, for the execution trace.
+type Step struct {
+	N           int
+	Instruction Instruction
+	Before      State
+	After       State
+}
+
+// Machine runs a program.
+type Machine struct {
+	Program Program
+	Ops     Ops
+	State   State
+	Steps   int
+
+	// Trace, if set, is called after each instruction runs.
+	Trace func(Step)
+
+	// Log gets a trace message for each instruction run.
+	Log *trace.Logger
+
+	visited []bool
+}
+
+// New returns a machine, ready to run prog with the default operations.
+func New(prog Program) *Machine {
+	return &Machine{Program: prog, Ops: DefaultOps(), Log: trace.Discard, visited: make([]bool, len(prog))}
+}
+
+// Reset puts the machine back the way it was before it ran anything.
+func (m *Machine) Reset() {
+	m.State = State{}
+	m.Steps = 0
+	m.visited = make([]bool, len(m.Program))
+}
+
+// Outcome reports whether the machine can run its next instruction, and if
+// not, why not.
+func (m *Machine) Outcome() Outcome {
+	switch pc := m.State.PC; {
+	case pc == len(m.Program):
+		return Halted
+	case pc < 0 || pc > len(m.Program):
+		return OutOfBounds
+	case m.visited[pc]:
+		return Looped
+	}
+	return Running
+}
+
+// Visited reports whether the instruction at pc has run.
+func (m *Machine) Visited(pc int) bool {
+	return pc >= 0 && pc < len(m.visited) && m.visited[pc]
+}
+
+// Current returns the next instruction to run, and false if the program
+// counter is outside the program.
+func (m *Machine) Current() (Instruction, bool) {
+	if pc := m.State.PC; pc >= 0 && pc < len(m.Program) {
+		return m.Program[pc], true
+	}
+	return Instruction{}, false
+}
+
+// Step runs the next instruction, if there is one to run, and returns the
+// outcome afterwards.
+func (m *Machine) Step() (Outcome, error) {
+	if o := m.Outcome(); o != Running {
+		return o, nil
+	}
+	var (
+		in     = m.Program[m.State.PC]
+		before = m.State
+	)
+	op, ok := m.Ops[in.Op]
+	if !ok {
+		return Running, fmt.Errorf("line %d: %w %q", in.Line, ErrUnknownOp, in.Op)
+	}
+	m.visited[m.State.PC] = true
+	op(&m.State, in.Arg)
+	m.Steps++
+
+	m.Log.Tracef("[%4d] %-8v pc=%d acc=%d", before.PC, in, m.State.PC, m.State.Acc)
+	if m.Trace != nil {
+		m.Trace(Step{N: m.Steps, Instruction: in, Before: before, After: m.State})
+	}
+	return m.Outcome(), nil
+}
+
+// Run runs the program until it halts, loops or goes out of bounds.
+func (m *Machine) Run() (Result, error) {
+	for {
+		o, err := m.Step()
+		if err != nil {
+			return Result{}, err
+		}
+		if o != Running {
+			return Result{Outcome: o, State: m.State, Steps: m.Steps}, nil
+		}
+	}
+}
+
+// Run runs prog from the start with the default operations.
+func Run(prog Program) (Result, error) {
+	return New(prog).Run()
+}
@@ -0,0 +1,118 @@
+package console
+
+import (
+	"errors"
+	"fmt"
+)
+
+// swaps are the operations that can be swapped for each other to repair a
+// program.
+var swaps = map[string]string{"jmp": "nop", "nop": "jmp"}
+
+// Next returns where the program counter goes after the instruction at pc
+// runs, going by the default operations: jmp jumps by its argument, and
+// everything else moves on to the next instruction.
+func Next(in Instruction, pc int) int {
+	if in.Op == "jmp" {
+		return pc + in.Arg
+	}
+	return pc + 1
+}
+
+// Terminating reports, for each instruction and for the end of the program,
+// whether running from there halts. It works backwards from the end, so it
+// takes time in proportion to the length of the program.
+func Terminating(prog Program) []bool {
+	halts, _ := terminating(prog)
+	return halts
+}
+
+// terminating is Terminating, and also returns the instructions that halt in
+// the order it found them, which puts each one after the one it goes to next.
+func terminating(prog Program) ([]bool, []int) {
+	n := len(prog)
+	from := make([][]int, n+1)
+	for pc, in := range prog {
+		if next := Next(in, pc); next >= 0 && next <= n {
+			from[next] = append(from[next], pc)
+		}
+	}
+
+	halts := make([]bool, n+1)
+	halts[n] = true
+	order := []int{n}
+	for i := 0; i < len(order); i++ {
+		for _, prev := range from[order[i]] {
+			if !halts[prev] {
+				halts[prev] = true
+				order = append(order, prev)
+			}
+		}
+	}
+	return halts, order
+}
+
+// Patch is a change to a single instruction.
+type Patch struct {
+	PC       int
+	From, To Instruction
+}
+
+func (p Patch) String() string {
+	return fmt.Sprintf("line %d: %v -> %v", p.From.Line, p.From, p.To)
+}
+
+// Apply returns a copy of prog with the patch made.
+func (p Patch) Apply(prog Program) Program {
+	patched := prog.Clone()
+	patched[p.PC] = p.To
+	return patched
+}
+
+// ErrHalts is returned when asked to repair a program that isn't broken.
+var ErrHalts = errors.New("program already halts")
+
+// Repairs returns every swap of a jmp for a nop, or a nop for a jmp, that makes
+// prog halt, in the order the program would reach them. Only the instructions
+// the program runs before it loops can make a difference, and swapping one of
+// those fixes the program if it lands somewhere that goes on to halt.
+func Repairs(prog Program) ([]Patch, error) {
+	var (
+		halts   = Terminating(prog)
+		visited = make([]bool, len(prog))
+		patches []Patch
+	)
+	for pc := 0; ; pc = Next(prog[pc], pc) {
+		switch {
+		case pc == len(prog):
+			return nil, ErrHalts
+		case pc < 0 || pc > len(prog) || visited[pc]:
+			return patches, nil
+		}
+		visited[pc] = true
+
+		in := prog[pc]
+		op, ok := swaps[in.Op]
+		if !ok {
+			continue
+		}
+		to := Instruction{Op: op, Arg: in.Arg, Line: in.Line}
+		if next := Next(to, pc); next >= 0 && next <= len(prog) && halts[next] {
+			patches = append(patches, Patch{PC: pc, From: in, To: to})
+		}
+	}
+}
+
+// Repair finds the first swap of a jmp for a nop, or a nop for a jmp, that
+// makes prog halt, and runs the patched program.
+func Repair(prog Program) (Patch, Result, error) {
+	patches, err := Repairs(prog)
+	if err != nil {
+		return Patch{}, Result{}, err
+	}
+	if len(patches) == 0 {
+		return Patch{}, Result{}, errors.New("no single swap makes the program halt")
+	}
+	res, err := Run(patches[0].Apply(prog))
+	return patches[0], res, err
+}
@@ -0,0 +1,52 @@
+package console
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTerminating(t *testing.T) {
+	halts := Terminating(mustParse(t, sample))
+	want := []bool{false, false, false, false, false, false, false, false, true, true}
+	for pc := range want {
+		if halts[pc] != want[pc] {
+			t.Errorf("%d: expected %t, but got %t", pc, want[pc], halts[pc])
+		}
+	}
+}
+
+func TestRepair(t *testing.T) {
+	patch, res, err := Repair(mustParse(t, sample))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "line 8: jmp -4 -> nop -4"; patch.String() != want {
+		t.Errorf("expected %q, but got %q", want, patch)
+	}
+	if res.Outcome != Halted || res.State.Acc != 8 {
+		t.Errorf("expected to halt with acc=8, but got %v with acc=%d", res.Outcome, res.State.Acc)
+	}
+
+	tt := []struct {
+		name    string
+		src     string
+		patches int
+		err     error
+	}{
+		{name: "halts", src: "nop +0\nacc +1", err: ErrHalts},
+		{name: "unfixable", src: "nop +0\njmp -1\njmp -1"},
+		{name: "two fixes", src: "nop +2\njmp -1\nacc +0", patches: 2},
+		{name: "off the end", src: "nop +5\njmp -1\njmp -1"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			patches, err := Repairs(mustParse(t, tc.src))
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error %v, but got %v", tc.err, err)
+			}
+			if len(patches) != tc.patches {
+				t.Fatalf("expected %d patches, but got %v", tc.patches, patches)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Package difftest generates random puzzle inputs and shrinks the ones that
+// make a solution fail down to something small enough to debug by hand.
+//
+// Inputs are handled as lines of text, the same as they'd be read from an
+// input file, so that generated inputs go through the same parsing as the
+// real thing.
+package difftest
+
+import (
+	"math/rand"
+	"strings"
+)
+
+// Generator returns a random, valid puzzle input. Size is up to the
+// generator, but is usually the number of lines or items in the input.
+type Generator func(rng *rand.Rand, size int) []string
+
+// Shrinker returns smaller versions of an input to try in its place, most
+// promising first.
+type Shrinker func(lines []string) [][]string
+
+// Shrink returns the smallest input it can find that still fails, by
+// repeatedly replacing the input with the first smaller version that fails.
+func Shrink(lines []string, shrink Shrinker, fails func([]string) bool) []string {
+	if shrink == nil {
+		shrink = Lines
+	}
+	for {
+		var smaller []string
+		for _, c := range shrink(lines) {
+			if fails(c) {
+				smaller = c
+				break
+			}
+		}
+		if smaller == nil {
+			return lines
+		}
+		lines = smaller
+	}
+}
+
+// Lines shrinks an input by removing lines: first half of them, then a
+// quarter, and so on down to one line at a time.
+func Lines(lines []string) [][]string {
+	return removeChunks(lines)
+}
+
+// Items shrinks an input by removing items from a list in one line, such as
+// a comma-separated list of numbers.
+func Items(line int, sep string) Shrinker {
+	return func(lines []string) [][]string {
+		if line >= len(lines) {
+			return nil
+		}
+		var candidates [][]string
+		for _, items := range removeChunks(strings.Split(lines[line], sep)) {
+			if len(items) == 0 {
+				continue
+			}
+			candidates = append(candidates, replaceLine(lines, line, strings.Join(items, sep)))
+		}
+		return candidates
+	}
+}
+
+// Replace shrinks an input by replacing items in a list in one line with a
+// placeholder, one at a time, such as an "x" for a bus that's out of
+// service.
+func Replace(line int, sep, placeholder string) Shrinker {
+	return func(lines []string) [][]string {
+		if line >= len(lines) {
+			return nil
+		}
+		var (
+			items      = strings.Split(lines[line], sep)
+			candidates [][]string
+		)
+		for i, item := range items {
+			if item == placeholder {
+				continue
+			}
+			replaced := append([]string(nil), items...)
+			replaced[i] = placeholder
+			candidates = append(candidates, replaceLine(lines, line, strings.Join(replaced, sep)))
+		}
+		return candidates
+	}
+}
+
+// Chain tries the candidates of each shrinker in turn.
+func Chain(shrinkers ...Shrinker) Shrinker {
+	return func(lines []string) [][]string {
+		var candidates [][]string
+		for _, s := range shrinkers {
+			candidates = append(candidates, s(lines)...)
+		}
+		return candidates
+	}
+}
+
+// removeChunks returns copies of s with chunks removed, largest chunks
+// first.
+func removeChunks(s []string) [][]string {
+	var candidates [][]string
+	for size := len(s) / 2; size > 0; size /= 2 {
+		for start := 0; start < len(s); start += size {
+			end := start + size
+			if end > len(s) {
+				end = len(s)
+			}
+			c := make([]string, 0, len(s)-(end-start))
+			c = append(c, s[:start]...)
+			c = append(c, s[end:]...)
+			candidates = append(candidates, c)
+		}
+	}
+	return candidates
+}
+
+func replaceLine(lines []string, i int, line string) []string {
+	c := append([]string(nil), lines...)
+	c[i] = line
+	return c
+}
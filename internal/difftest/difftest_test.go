@@ -0,0 +1,67 @@
+package difftest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func contains(lines []string, want ...string) bool {
+	joined := "," + strings.Join(lines, ",") + ","
+	for _, w := range want {
+		if !strings.Contains(joined, ","+w+",") {
+			return false
+		}
+	}
+	return true
+}
+
+func TestShrink(t *testing.T) {
+	tt := []struct {
+		name   string
+		in     []string
+		shrink Shrinker
+		fails  func([]string) bool
+		want   []string
+	}{
+		{
+			name:  "lines",
+			in:    strings.Split("1 2 3 4 5 6 7 8 9 10 11", " "),
+			fails: func(l []string) bool { return contains(l, "3", "8") },
+			want:  []string{"3", "8"},
+		},
+		{
+			name:   "items",
+			in:     []string{"939", "7,13,x,x,59,x,31,19"},
+			shrink: Items(1, ","),
+			fails:  func(l []string) bool { return contains(strings.Split(l[1], ","), "59", "19") },
+			want:   []string{"939", "59,19"},
+		},
+		{
+			name:   "replace",
+			in:     []string{"939", "7,13,x,x,59,x,31,19"},
+			shrink: Chain(Lines, Replace(1, ",", "x")),
+			fails: func(l []string) bool {
+				return len(l) == 2 && contains(strings.Split(l[1], ","), "13")
+			},
+			want: []string{"939", "x,13,x,x,x,x,x,x"},
+		},
+		{
+			name:  "nothing fails",
+			in:    []string{"a", "b"},
+			fails: func(l []string) bool { return false },
+			want:  []string{"a", "b"},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if !tc.fails(tc.in) && tc.name != "nothing fails" {
+				t.Fatalf("test input doesn't fail to begin with")
+			}
+			got := Shrink(tc.in, tc.shrink, tc.fails)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("expected %q, but got %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+// Package fetch downloads puzzle input from Advent of Code, optionally
+// waiting for a puzzle to unlock first.
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/calendar"
+	"github.com/ianfoo/advent-of-code-2020/internal/clock"
+)
+
+const (
+	// DefaultBaseURL is where the real site lives.
+	DefaultBaseURL = "https://adventofcode.com"
+
+	// UserAgent identifies requests from this tool, as the site asks of
+	// automated tools.
+	UserAgent = "github.com/ianfoo/advent-of-code-2020 admin"
+)
+
+var (
+	// ErrNotAvailable is returned when the site doesn't have input for a
+	// puzzle yet, usually because it hasn't unlocked.
+	ErrNotAvailable = errors.New("puzzle input not available yet")
+
+	// ErrUnauthorized is returned when the site rejects the session token.
+	ErrUnauthorized = errors.New("session token rejected: log in again")
+)
+
+// Backoff controls how requests are retried while input is unavailable.
+// Delays start at Initial and double after each attempt, up to Max.
+type Backoff struct {
+	Initial  time.Duration
+	Max      time.Duration
+	Attempts int
+}
+
+// DefaultBackoff keeps trying for a few minutes without hammering the site
+// right at unlock time, when everyone else is hammering it too.
+var DefaultBackoff = Backoff{
+	Initial:  time.Second,
+	Max:      30 * time.Second,
+	Attempts: 12,
+}
+
+// Client fetches puzzle input.
+type Client struct {
+	BaseURL string
+	HTTP    *http.Client
+	Clock   clock.Clock
+	Backoff Backoff
+}
+
+// NewClient returns a client for the real site, using the wall clock.
+func NewClient() Client {
+	return Client{
+		BaseURL: DefaultBaseURL,
+		HTTP:    http.DefaultClient,
+		Clock:   clock.Real{},
+		Backoff: DefaultBackoff,
+	}
+}
+
+// Input makes a single attempt to download input for a puzzle.
+func (c Client) Input(ctx context.Context, year, day int, token string) ([]byte, error) {
+	if err := calendar.Validate(year, day); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/%d/day/%d/input", c.BaseURL, year, day)
+	r, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	r = r.WithContext(ctx)
+	r.Header.Set("User-Agent", UserAgent)
+	r.AddCookie(&http.Cookie{Name: "session", Value: token})
+
+	resp, err := c.HTTP.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return ioutil.ReadAll(resp.Body)
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrNotAvailable
+	case resp.StatusCode == http.StatusBadRequest,
+		resp.StatusCode == http.StatusUnauthorized,
+		resp.StatusCode == http.StatusForbidden:
+		return nil, ErrUnauthorized
+	default:
+		return nil, fmt.Errorf("fetching %s: unexpected status %s", url, resp.Status)
+	}
+}
+
+// InputWithRetry downloads input for a puzzle, retrying with exponential
+// backoff while the input is unavailable or the site is having trouble. It
+// gives up immediately if the puzzle doesn't exist or the token is rejected.
+func (c Client) InputWithRetry(ctx context.Context, year, day int, token string) ([]byte, error) {
+	if err := calendar.Validate(year, day); err != nil {
+		return nil, err
+	}
+	var (
+		delay   = c.Backoff.Initial
+		lastErr error
+	)
+	for attempt := 1; attempt <= c.Backoff.Attempts; attempt++ {
+		input, err := c.Input(ctx, year, day, token)
+		if err == nil {
+			return input, nil
+		}
+		if errors.Is(err, ErrUnauthorized) || ctx.Err() != nil {
+			return nil, err
+		}
+		lastErr = err
+		if attempt == c.Backoff.Attempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-c.Clock.After(delay):
+		}
+		if delay *= 2; delay > c.Backoff.Max {
+			delay = c.Backoff.Max
+		}
+	}
+	return nil, fmt.Errorf("giving up after %d attempts: %w", c.Backoff.Attempts, lastErr)
+}
+
+// WaitUntil blocks until the clock reaches t, calling report with the time
+// remaining every so often: rarely when unlock is far off, and every second
+// for the final countdown.
+func (c Client) WaitUntil(ctx context.Context, t time.Time, report func(remaining time.Duration)) error {
+	for {
+		remaining := t.Sub(c.Clock.Now())
+		if remaining <= 0 {
+			return nil
+		}
+		if report != nil {
+			report(remaining)
+		}
+
+		// Wake up on the next round interval, so the countdown reads
+		// 10m0s, 9m0s... instead of drifting.
+		step := reportInterval(remaining)
+		wait := remaining % step
+		if wait == 0 {
+			wait = step
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.Clock.After(wait):
+		}
+	}
+}
+
+func reportInterval(remaining time.Duration) time.Duration {
+	switch {
+	case remaining > time.Hour:
+		return 10 * time.Minute
+	case remaining > 10*time.Minute:
+		return time.Minute
+	case remaining > time.Minute:
+		return 10 * time.Second
+	default:
+		return time.Second
+	}
+}
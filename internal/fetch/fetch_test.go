@@ -0,0 +1,111 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/clock"
+)
+
+func TestWaitAndFetch(t *testing.T) {
+	var (
+		est    = time.FixedZone("EST", -5*60*60)
+		unlock = time.Date(2020, 12, 5, 0, 0, 0, 0, est)
+		clk    = clock.NewFake(unlock.Add(-90 * time.Minute))
+
+		// The site's clock runs a little behind ours.
+		skew     = 3 * time.Second
+		requests int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/2020/day/5/input" {
+			t.Errorf("unexpected request path %s", r.URL.Path)
+		}
+		if c, err := r.Cookie("session"); err != nil || c.Value != "token" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if clk.Now().Before(unlock.Add(skew)) {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintln(w, "BFFFBBFRRR")
+	}))
+	defer srv.Close()
+
+	c := Client{
+		BaseURL: srv.URL,
+		HTTP:    srv.Client(),
+		Clock:   clk,
+		Backoff: DefaultBackoff,
+	}
+
+	var reports []time.Duration
+	err := c.WaitUntil(context.Background(), unlock, func(remaining time.Duration) {
+		reports = append(reports, remaining)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if now := clk.Now(); !now.Equal(unlock) {
+		t.Fatalf("expected wait to end at %v, but it ended at %v", unlock, now)
+	}
+	if first, last := reports[0], reports[len(reports)-1]; first != 90*time.Minute || last != time.Second {
+		t.Fatalf("expected countdown from 1h30m0s to 1s, but got %v to %v", first, last)
+	}
+
+	input, err := c.InputWithRetry(context.Background(), 2020, 5, "token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := string(input), "BFFFBBFRRR\n"; got != want {
+		t.Fatalf("expected input %q, but got %q", want, got)
+	}
+
+	// Two misses before the skew passes (at +0s and +1s, then +3s succeeds).
+	if requests != 3 {
+		t.Fatalf("expected 3 requests, but got %d", requests)
+	}
+}
+
+func TestInputWithRetryGivesUp(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	clk := clock.NewFake(time.Date(2020, 12, 5, 0, 0, 0, 0, time.UTC))
+	c := Client{
+		BaseURL: srv.URL,
+		HTTP:    srv.Client(),
+		Clock:   clk,
+		Backoff: Backoff{Initial: time.Second, Max: 4 * time.Second, Attempts: 5},
+	}
+	if _, err := c.InputWithRetry(context.Background(), 2020, 5, "token"); !errors.Is(err, ErrNotAvailable) {
+		t.Fatalf("expected %v, but got %v", ErrNotAvailable, err)
+	}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second}
+	if got := clk.Waits(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("expected backoff %v, but got %v", want, got)
+	}
+}
+
+func TestInputUnauthorized(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	clk := clock.NewFake(time.Now())
+	c := Client{BaseURL: srv.URL, HTTP: srv.Client(), Clock: clk, Backoff: DefaultBackoff}
+	if _, err := c.InputWithRetry(context.Background(), 2020, 5, "bad"); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected %v, but got %v", ErrUnauthorized, err)
+	}
+	if waits := clk.Waits(); len(waits) != 0 {
+		t.Fatalf("expected no retries, but waited %v", waits)
+	}
+}
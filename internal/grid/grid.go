@@ -0,0 +1,304 @@
+// Package grid provides a two dimensional grid of characters, the shape a
+// good share of Advent of Code puzzles come in.
+package grid
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Point is a location in a grid. X grows to the right and Y grows downward,
+// so {0, 0} is the top left corner.
+type Point struct {
+	X, Y int
+}
+
+// Add returns the sum of p and q.
+func (p Point) Add(q Point) Point {
+	return Point{p.X + q.X, p.Y + q.Y}
+}
+
+// Scale returns p with both coordinates multiplied by n.
+func (p Point) Scale(n int) Point {
+	return Point{p.X * n, p.Y * n}
+}
+
+func (p Point) String() string {
+	return fmt.Sprintf("(%d,%d)", p.X, p.Y)
+}
+
+// Directions to neighboring cells.
+var (
+	Up        = Point{0, -1}
+	Down      = Point{0, 1}
+	Left      = Point{-1, 0}
+	Right     = Point{1, 0}
+	UpLeft    = Point{-1, -1}
+	UpRight   = Point{1, -1}
+	DownLeft  = Point{-1, 1}
+	DownRight = Point{1, 1}
+
+	// Orthogonal are the four directions that share an edge.
+	Orthogonal = []Point{Up, Right, Down, Left}
+
+	// Adjacent are all eight directions that share an edge or a corner.
+	Adjacent = []Point{UpLeft, Up, UpRight, Left, Right, DownLeft, Down, DownRight}
+)
+
+// Wrap says which edges of a grid join up with the opposite edge.
+type Wrap int
+
+const (
+	NoWrap   Wrap = 0
+	WrapX    Wrap = 1
+	WrapY    Wrap = 2
+	WrapBoth      = WrapX | WrapY
+)
+
+// Grid is a rectangle of runes.
+type Grid struct {
+	cells         [][]rune
+	width, height int
+
+	// Wrap makes points past an edge refer to the other side of the grid.
+	Wrap Wrap
+}
+
+// New returns a width by height grid filled with fill.
+func New(width, height int, fill rune) *Grid {
+	cells := make([][]rune, height)
+	for y := range cells {
+		cells[y] = make([]rune, width)
+		for x := range cells[y] {
+			cells[y][x] = fill
+		}
+	}
+	return &Grid{cells: cells, width: width, height: height}
+}
+
+// FromRunes returns a grid holding rows, which must all be the same width.
+// The rows are used as they are, not copied.
+func FromRunes(rows [][]rune) (*Grid, error) {
+	if len(rows) == 0 {
+		return nil, errors.New("grid has no rows")
+	}
+	width := len(rows[0])
+	for y, row := range rows {
+		if len(row) != width {
+			return nil, fmt.Errorf("row %d is %d wide, but the first row is %d wide", y, len(row), width)
+		}
+	}
+	return &Grid{cells: rows, width: width, height: len(rows)}, nil
+}
+
+// FromLines returns a grid with one row per line.
+func FromLines(lines []string) (*Grid, error) {
+	rows := make([][]rune, len(lines))
+	for y, line := range lines {
+		rows[y] = []rune(line)
+	}
+	return FromRunes(rows)
+}
+
+// Width is the number of columns in the grid.
+func (g *Grid) Width() int { return g.width }
+
+// Height is the number of rows in the grid.
+func (g *Grid) Height() int { return g.height }
+
+// Resolve applies wrapping to p, and reports whether the result lies in the
+// grid.
+func (g *Grid) Resolve(p Point) (Point, bool) {
+	if g.Wrap&WrapX != 0 {
+		p.X = mod(p.X, g.width)
+	}
+	if g.Wrap&WrapY != 0 {
+		p.Y = mod(p.Y, g.height)
+	}
+	return p, p.X >= 0 && p.X < g.width && p.Y >= 0 && p.Y < g.height
+}
+
+func mod(a, n int) int {
+	return ((a % n) + n) % n
+}
+
+// InBounds reports whether p refers to a cell of the grid.
+func (g *Grid) InBounds(p Point) bool {
+	_, ok := g.Resolve(p)
+	return ok
+}
+
+// At returns the rune at p, and false if p is out of bounds.
+func (g *Grid) At(p Point) (rune, bool) {
+	p, ok := g.Resolve(p)
+	if !ok {
+		return 0, false
+	}
+	return g.cells[p.Y][p.X], true
+}
+
+// Set changes the rune at p, and reports whether p was in bounds.
+func (g *Grid) Set(p Point, r rune) bool {
+	p, ok := g.Resolve(p)
+	if !ok {
+		return false
+	}
+	g.cells[p.Y][p.X] = r
+	return true
+}
+
+// Each calls fn for every cell, row by row.
+func (g *Grid) Each(fn func(p Point, r rune)) {
+	for y, row := range g.cells {
+		for x, r := range row {
+			fn(Point{x, y}, r)
+		}
+	}
+}
+
+// Count returns how many cells hold r.
+func (g *Grid) Count(r rune) int {
+	var n int
+	g.Each(func(_ Point, c rune) {
+		if c == r {
+			n++
+		}
+	})
+	return n
+}
+
+// Neighbors returns the in-bounds points one step from p in each of dirs,
+// such as Orthogonal or Adjacent.
+func (g *Grid) Neighbors(p Point, dirs []Point) []Point {
+	neighbors := make([]Point, 0, len(dirs))
+	for _, d := range dirs {
+		if n, ok := g.Resolve(p.Add(d)); ok {
+			neighbors = append(neighbors, n)
+		}
+	}
+	return neighbors
+}
+
+// CountNeighbors returns how many of the neighbors of p in dirs hold r.
+func (g *Grid) CountNeighbors(p Point, dirs []Point, r rune) int {
+	var n int
+	for _, q := range g.Neighbors(p, dirs) {
+		if g.cells[q.Y][q.X] == r {
+			n++
+		}
+	}
+	return n
+}
+
+// Ray walks from p in steps of dir, not counting p itself, until it finds a
+// cell for which match returns true. It returns false if the walk leaves the
+// grid, or, on a wrapping grid, comes back around without a match.
+func (g *Grid) Ray(p, dir Point, match func(rune) bool) (Point, bool) {
+	start, _ := g.Resolve(p)
+	if dir == (Point{}) {
+		return Point{}, false
+	}
+	for n := 1; n <= g.width*g.height; n++ {
+		q, ok := g.Resolve(p.Add(dir.Scale(n)))
+		if !ok || q == start {
+			return Point{}, false
+		}
+		if match(g.cells[q.Y][q.X]) {
+			return q, true
+		}
+	}
+	return Point{}, false
+}
+
+// Clone returns a copy of g.
+func (g *Grid) Clone() *Grid {
+	return g.build(g.width, g.height, g.Wrap, func(x, y int) rune {
+		return g.cells[y][x]
+	})
+}
+
+// Equal reports whether g and other hold the same cells.
+func (g *Grid) Equal(other *Grid) bool {
+	if g.width != other.width || g.height != other.height {
+		return false
+	}
+	for y, row := range g.cells {
+		for x, r := range row {
+			if other.cells[y][x] != r {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Transpose returns g flipped over its main diagonal, so rows become columns.
+func (g *Grid) Transpose() *Grid {
+	return g.build(g.height, g.width, swapWrap(g.Wrap), func(x, y int) rune {
+		return g.cells[x][y]
+	})
+}
+
+// RotateClockwise returns g turned a quarter turn clockwise.
+func (g *Grid) RotateClockwise() *Grid {
+	return g.build(g.height, g.width, swapWrap(g.Wrap), func(x, y int) rune {
+		return g.cells[g.height-1-x][y]
+	})
+}
+
+// RotateCounterClockwise returns g turned a quarter turn counterclockwise.
+func (g *Grid) RotateCounterClockwise() *Grid {
+	return g.build(g.height, g.width, swapWrap(g.Wrap), func(x, y int) rune {
+		return g.cells[x][g.width-1-y]
+	})
+}
+
+// FlipHorizontal returns g mirrored left to right.
+func (g *Grid) FlipHorizontal() *Grid {
+	return g.build(g.width, g.height, g.Wrap, func(x, y int) rune {
+		return g.cells[y][g.width-1-x]
+	})
+}
+
+// FlipVertical returns g mirrored top to bottom.
+func (g *Grid) FlipVertical() *Grid {
+	return g.build(g.width, g.height, g.Wrap, func(x, y int) rune {
+		return g.cells[g.height-1-y][x]
+	})
+}
+
+// build makes a new grid, getting the rune for each cell from at.
+func (g *Grid) build(width, height int, wrap Wrap, at func(x, y int) rune) *Grid {
+	n := New(width, height, 0)
+	n.Wrap = wrap
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			n.cells[y][x] = at(x, y)
+		}
+	}
+	return n
+}
+
+func swapWrap(w Wrap) Wrap {
+	var swapped Wrap
+	if w&WrapX != 0 {
+		swapped |= WrapY
+	}
+	if w&WrapY != 0 {
+		swapped |= WrapX
+	}
+	return swapped
+}
+
+// String renders the grid one row per line.
+func (g *Grid) String() string {
+	var b strings.Builder
+	for y, row := range g.cells {
+		if y > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(string(row))
+	}
+	return b.String()
+}
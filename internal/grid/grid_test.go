@@ -0,0 +1,164 @@
+package grid
+
+import (
+	"testing"
+)
+
+func mustGrid(t *testing.T, lines ...string) *Grid {
+	t.Helper()
+	g, err := FromLines(lines)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return g
+}
+
+func TestFromLinesRagged(t *testing.T) {
+	if _, err := FromLines([]string{"abc", "de"}); err == nil {
+		t.Fatal("expected error for ragged rows, but got none")
+	}
+	if _, err := FromLines(nil); err == nil {
+		t.Fatal("expected error for empty grid, but got none")
+	}
+}
+
+func TestAtAndWrap(t *testing.T) {
+	g := mustGrid(t,
+		"abc",
+		"def",
+	)
+	tt := []struct {
+		name string
+		wrap Wrap
+		p    Point
+		want rune
+		ok   bool
+	}{
+		{name: "inside", p: Point{1, 1}, want: 'e', ok: true},
+		{name: "past right edge", p: Point{3, 0}},
+		{name: "above top", p: Point{0, -1}},
+		{name: "wrap x", wrap: WrapX, p: Point{4, 0}, want: 'b', ok: true},
+		{name: "wrap x negative", wrap: WrapX, p: Point{-1, 1}, want: 'f', ok: true},
+		{name: "wrap x only", wrap: WrapX, p: Point{0, 2}},
+		{name: "wrap y", wrap: WrapY, p: Point{2, -3}, want: 'f', ok: true},
+		{name: "wrap both", wrap: WrapBoth, p: Point{7, 7}, want: 'e', ok: true},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			g.Wrap = tc.wrap
+			got, ok := g.At(tc.p)
+			if ok != tc.ok || got != tc.want {
+				t.Fatalf("expected %q, %t, but got %q, %t", tc.want, tc.ok, got, ok)
+			}
+		})
+	}
+}
+
+func TestNeighbors(t *testing.T) {
+	g := mustGrid(t,
+		"#.#",
+		"..#",
+		"###",
+	)
+	tt := []struct {
+		name string
+		wrap Wrap
+		p    Point
+		dirs []Point
+		want int
+		n    int
+	}{
+		{name: "corner orthogonal", p: Point{0, 0}, dirs: Orthogonal, want: 0, n: 2},
+		{name: "corner adjacent", p: Point{0, 0}, dirs: Adjacent, want: 0, n: 3},
+		{name: "center adjacent", p: Point{1, 1}, dirs: Adjacent, want: 6, n: 8},
+		{name: "center orthogonal", p: Point{1, 1}, dirs: Orthogonal, want: 2, n: 4},
+		{name: "wrapped corner", wrap: WrapBoth, p: Point{0, 0}, dirs: Adjacent, want: 5, n: 8},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			g.Wrap = tc.wrap
+			if n := len(g.Neighbors(tc.p, tc.dirs)); n != tc.n {
+				t.Errorf("expected %d neighbors, but got %d", tc.n, n)
+			}
+			if got := g.CountNeighbors(tc.p, tc.dirs, '#'); got != tc.want {
+				t.Errorf("expected %d matching neighbors, but got %d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRay(t *testing.T) {
+	g := mustGrid(t,
+		"#....",
+		".....",
+		"..L.#",
+	)
+	notFloor := func(r rune) bool { return r != '.' }
+	tt := []struct {
+		name string
+		wrap Wrap
+		dir  Point
+		want Point
+		ok   bool
+	}{
+		{name: "right", dir: Right, want: Point{4, 2}, ok: true},
+		{name: "up left", dir: UpLeft, want: Point{0, 0}, ok: true},
+		{name: "left", dir: Left},
+		{name: "none", dir: Point{}},
+		{name: "wrap left", wrap: WrapX, dir: Left, want: Point{4, 2}, ok: true},
+		{name: "wrap around to nothing", wrap: WrapBoth, dir: Up},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			g.Wrap = tc.wrap
+			got, ok := g.Ray(Point{2, 2}, tc.dir, notFloor)
+			if ok != tc.ok || (ok && got != tc.want) {
+				t.Fatalf("expected %v, %t, but got %v, %t", tc.want, tc.ok, got, ok)
+			}
+		})
+	}
+}
+
+func TestTransforms(t *testing.T) {
+	g := mustGrid(t,
+		"abc",
+		"def",
+	)
+	tt := []struct {
+		name string
+		got  *Grid
+		want string
+	}{
+		{name: "transpose", got: g.Transpose(), want: "ad\nbe\ncf"},
+		{name: "rotate clockwise", got: g.RotateClockwise(), want: "da\neb\nfc"},
+		{name: "rotate counterclockwise", got: g.RotateCounterClockwise(), want: "cf\nbe\nad"},
+		{name: "flip horizontal", got: g.FlipHorizontal(), want: "cba\nfed"},
+		{name: "flip vertical", got: g.FlipVertical(), want: "def\nabc"},
+		{name: "full turn", got: g.RotateClockwise().RotateClockwise().RotateClockwise().RotateClockwise(), want: g.String()},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.got.String(); got != tc.want {
+				t.Fatalf("expected\n%s\nbut got\n%s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestCloneAndEqual(t *testing.T) {
+	g := mustGrid(t, "ab", "cd")
+	c := g.Clone()
+	if !g.Equal(c) {
+		t.Fatal("expected clone to equal original")
+	}
+	c.Set(Point{0, 0}, 'z')
+	if g.Equal(c) {
+		t.Fatal("expected changed clone to differ from original")
+	}
+	if r, _ := g.At(Point{0, 0}); r != 'a' {
+		t.Fatalf("expected original to be unchanged, but got %q", r)
+	}
+	if n := c.Count('z'); n != 1 {
+		t.Fatalf("expected 1 'z', but got %d", n)
+	}
+}
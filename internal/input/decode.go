@@ -0,0 +1,210 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// TagName is the struct tag that names the regexp group a field is filled
+// from.
+const TagName = "aoc"
+
+// Decoder fills in structs from lines of input using a regexp with named
+// groups. Fields are matched to groups by their aoc tag:
+//
+//	type entry struct {
+//		Min      int    `aoc:"min"`
+//		Max      int    `aoc:"max"`
+//		Char     rune   `aoc:"letter,char"`
+//		Password string `aoc:"password"`
+//	}
+//
+// Fields may be any integer type, a string, or a slice of any of these.
+// Slices are split on whitespace unless the tag says otherwise, as in
+// `aoc:"nums,sep=,"`. Slices of slices take one sep per level, outermost
+// first: `aoc:"rows,sep=;,sep=,"`. A rune field marked char, right after the
+// group name, holds a single character rather than a number; a []rune marked
+// char with no sep holds the characters of the group. Without char, a rune is
+// just an int32.
+type Decoder struct {
+	re     *regexp.Regexp
+	groups map[string]int
+}
+
+// NewDecoder returns a Decoder for lines matching re.
+func NewDecoder(re *regexp.Regexp) *Decoder {
+	groups := make(map[string]int)
+	for i, name := range re.SubexpNames() {
+		if name != "" {
+			groups[name] = i
+		}
+	}
+	return &Decoder{re: re, groups: groups}
+}
+
+// DecodeLines decodes every non-blank line of input into the slice of structs
+// that dst points to.
+func DecodeLines(r io.Reader, re *regexp.Regexp, dst interface{}) error {
+	lines, err := Lines(r)
+	if err != nil {
+		return err
+	}
+	return NewDecoder(re).DecodeAll(lines, dst)
+}
+
+// DecodeAll decodes every non-blank line into the slice of structs that dst
+// points to.
+func (d *Decoder) DecodeAll(lines []string, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("decode: expected pointer to slice, but got %T", dst)
+	}
+	slice := v.Elem()
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		elem := reflect.New(slice.Type().Elem())
+		if err := d.Decode(line, i+1, elem.Interface()); err != nil {
+			return err
+		}
+		slice.Set(reflect.Append(slice, elem.Elem()))
+	}
+	return nil
+}
+
+// Decode fills in the struct that dst points to from a single line. The line
+// number is used for error reporting.
+func (d *Decoder) Decode(line string, lineNum int, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("decode: expected pointer to struct, but got %T", dst)
+	}
+	m := d.re.FindStringSubmatchIndex(line)
+	if m == nil {
+		return errorf(lineNum, 0, "%q does not match %s", line, d.re)
+	}
+
+	v = v.Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag, ok := t.Field(i).Tag.Lookup(TagName)
+		if !ok {
+			continue
+		}
+		name, seps, char := parseTag(tag)
+		group, ok := d.groups[name]
+		if !ok {
+			return fmt.Errorf("decode: field %s: no group named %q in %s", t.Field(i).Name, name, d.re)
+		}
+		start, end := m[2*group], m[2*group+1]
+		if start < 0 {
+			// Optional group that didn't match; leave the zero value.
+			continue
+		}
+		f := field{text: line[start:end], col: start + 1}
+		if err := setValue(v.Field(i), f, seps, char); err != nil {
+			col := f.col
+			var ce *colError
+			if errors.As(err, &ce) {
+				col, err = ce.col, ce.err
+			}
+			return &Error{Line: lineNum, Col: col, Err: fmt.Errorf("%s: %w", name, err)}
+		}
+	}
+	return nil
+}
+
+// parseTag splits a tag into the group name, its separators, and whether it
+// holds characters.
+func parseTag(tag string) (string, []string, bool) {
+	parts := strings.Split(tag, ",sep=")
+	name := strings.TrimSuffix(parts[0], ",char")
+	return name, parts[1:], name != parts[0]
+}
+
+var runeType = reflect.TypeOf(rune(0))
+
+// colError records which element of a slice could not be parsed.
+type colError struct {
+	col int
+	err error
+}
+
+func (e *colError) Error() string { return e.err.Error() }
+
+// setValue parses f into v. If char is set, integers are read as single
+// characters.
+func setValue(v reflect.Value, f field, seps []string, char bool) error {
+	if v.Kind() == reflect.Slice {
+		return setSlice(v, f, seps, char)
+	}
+
+	switch {
+	case char && v.Type() == runeType:
+		r, size := utf8.DecodeRuneInString(f.text)
+		if size == 0 || size != len(f.text) {
+			return fmt.Errorf("expected a single character, but got %q", f.text)
+		}
+		v.SetInt(int64(r))
+	case v.Kind() == reflect.String:
+		v.SetString(f.text)
+	case v.Kind() >= reflect.Int && v.Kind() <= reflect.Int64:
+		n, err := strconv.ParseInt(f.text, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", f.text)
+		}
+		v.SetInt(n)
+	case v.Kind() >= reflect.Uint && v.Kind() <= reflect.Uint64:
+		n, err := strconv.ParseUint(f.text, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", f.text)
+		}
+		v.SetUint(n)
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
+
+func setSlice(v reflect.Value, f field, seps []string, char bool) error {
+	var (
+		sep    string
+		fields []field
+	)
+	if len(seps) > 0 {
+		sep, seps = seps[0], seps[1:]
+	}
+	switch {
+	case sep == "" && char && v.Type().Elem() == runeType:
+		// The characters of the group, one per element.
+		for i, ch := range f.text {
+			fields = append(fields, field{string(ch), i + 1})
+		}
+	case strings.TrimSpace(f.text) == "":
+		// Nothing to split; leave the slice empty.
+	default:
+		fields = split(f.text, sep)
+	}
+
+	s := reflect.MakeSlice(v.Type(), len(fields), len(fields))
+	for i, elem := range fields {
+		// Columns from split are relative to the group.
+		elem.col += f.col - 1
+		if err := setValue(s.Index(i), elem, seps, char); err != nil {
+			var ce *colError
+			if errors.As(err, &ce) {
+				return err
+			}
+			return &colError{col: elem.col, err: err}
+		}
+	}
+	v.Set(s)
+	return nil
+}
@@ -0,0 +1,125 @@
+package input
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestDecodeLines(t *testing.T) {
+	type entry struct {
+		Min      int    `aoc:"min"`
+		Max      uint8  `aoc:"max"`
+		Char     rune   `aoc:"char,char"`
+		Password string `aoc:"password"`
+		Letters  []rune `aoc:"password,char"`
+		Ignored  string
+	}
+	re := regexp.MustCompile(`^(?P<min>\d+)-(?P<max>\d+) (?P<char>\w): (?P<password>\w+)$`)
+
+	var got []entry
+	if err := DecodeLines(strings.NewReader("1-3 a: abcde\n\n2-9 c: ccc\n"), re, &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []entry{
+		{Min: 1, Max: 3, Char: 'a', Password: "abcde", Letters: []rune("abcde")},
+		{Min: 2, Max: 9, Char: 'c', Password: "ccc", Letters: []rune("ccc")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, but got %+v", want, got)
+	}
+}
+
+func TestDecodeSlices(t *testing.T) {
+	type row struct {
+		Name  string   `aoc:"name"`
+		Nums  []int    `aoc:"nums,sep=,"`
+		Words []string `aoc:"words"`
+		Grid  [][]int  `aoc:"grid,sep=;,sep=,"`
+		Opt   []int    `aoc:"opt"`
+	}
+	re := regexp.MustCompile(`^(?P<name>\w+): (?P<nums>[\d, ]*) \| (?P<words>[a-z ]+) \| (?P<grid>[\d,;]+)(?: \| (?P<opt>.*))?$`)
+
+	var got row
+	err := NewDecoder(re).Decode("x: 1, 2,3 | foo  bar | 1,2;3,4", 1, &got)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := row{
+		Name:  "x",
+		Nums:  []int{1, 2, 3},
+		Words: []string{"foo", "bar"},
+		Grid:  [][]int{{1, 2}, {3, 4}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, but got %+v", want, got)
+	}
+}
+
+func TestDecodeInt32(t *testing.T) {
+	type entry struct {
+		N    int32   `aoc:"n"`
+		Nums []int32 `aoc:"nums,sep=,"`
+		C    rune    `aoc:"c,char"`
+	}
+	re := regexp.MustCompile(`^(?P<n>\d+) (?P<nums>[\d,]+) (?P<c>.)$`)
+
+	var got entry
+	if err := NewDecoder(re).Decode("42 7,8 x", 1, &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := (entry{N: 42, Nums: []int32{7, 8}, C: 'x'}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, but got %+v", want, got)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	type entry struct {
+		N    int    `aoc:"n"`
+		C    rune   `aoc:"c,char"`
+		Nums []int  `aoc:"nums,sep=,"`
+		S    string `aoc:"s"`
+	}
+	re := regexp.MustCompile(`^(?P<n>\S+) (?P<c>\S+) (?P<nums>\S+) (?P<s>\S+)$`)
+
+	tt := []struct {
+		name      string
+		in        string
+		line, col int
+	}{
+		{name: "no match", in: "1 a 2\n", line: 1, col: 0},
+		{name: "bad int", in: "1 a 2 x\n12x a 2 x\n", line: 2, col: 1},
+		{name: "bad rune", in: "1 ab 2 x\n", line: 1, col: 3},
+		{name: "bad slice element", in: "1 a 2,3,z x\n", line: 1, col: 9},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var entries []entry
+			err := DecodeLines(strings.NewReader(tc.in), re, &entries)
+			var ie *Error
+			if !errors.As(err, &ie) {
+				t.Fatalf("expected *Error, but got %v", err)
+			}
+			if ie.Line != tc.line || ie.Col != tc.col {
+				t.Fatalf("expected line %d, column %d, but got line %d, column %d (%v)",
+					tc.line, tc.col, ie.Line, ie.Col, err)
+			}
+		})
+	}
+}
+
+func TestDecodeBadTarget(t *testing.T) {
+	re := regexp.MustCompile(`(?P<n>\d+)`)
+	var missing struct {
+		N int `aoc:"m"`
+	}
+	if err := NewDecoder(re).Decode("1", 1, &missing); err == nil {
+		t.Errorf("expected error for unknown group, but got none")
+	}
+	var notSlice struct{}
+	if err := DecodeLines(strings.NewReader("1"), re, &notSlice); err == nil {
+		t.Errorf("expected error for non-slice target, but got none")
+	}
+}
@@ -0,0 +1,252 @@
+// Package input reads puzzle input in the handful of shapes Advent of Code
+// uses over and over: lines, numbers, comma separated lists, blank-line
+// separated groups, character grids, and key:value records. Lines with a
+// regular shape can be decoded into structs with a Decoder.
+//
+// Errors point at the line and column of the offending input.
+package input
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Error describes bad input and where it was found. Line and column numbers
+// are 1-based; a column of 0 means the whole line.
+type Error struct {
+	Line, Col int
+	Err       error
+}
+
+func (e *Error) Error() string {
+	if e.Col == 0 {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %d, column %d: %v", e.Line, e.Col, e.Err)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+func errorf(line, col int, format string, params ...interface{}) error {
+	return &Error{Line: line, Col: col, Err: fmt.Errorf(format, params...)}
+}
+
+// Lines reads every line of input.
+func Lines(r io.Reader) ([]string, error) {
+	var (
+		s     = bufio.NewScanner(r)
+		input []string
+	)
+	for s.Scan() {
+		input = append(input, s.Text())
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return input, nil
+}
+
+// Ints reads one integer per line. Blank lines are skipped.
+func Ints(r io.Reader) ([]int, error) {
+	lines, err := Lines(r)
+	if err != nil {
+		return nil, err
+	}
+	ints := make([]int, 0, len(lines))
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		n, err := strconv.Atoi(trimmed)
+		if err != nil {
+			col := strings.Index(line, trimmed) + 1
+			return nil, errorf(i+1, col, "invalid number %q", trimmed)
+		}
+		ints = append(ints, n)
+	}
+	return ints, nil
+}
+
+// CommaInts reads integers separated by commas, on any number of lines.
+func CommaInts(r io.Reader) ([]int, error) {
+	return separatedInts(r, ",")
+}
+
+// FieldInts reads integers separated by whitespace, on any number of lines.
+func FieldInts(r io.Reader) ([]int, error) {
+	return separatedInts(r, "")
+}
+
+func separatedInts(r io.Reader, sep string) ([]int, error) {
+	lines, err := Lines(r)
+	if err != nil {
+		return nil, err
+	}
+	var ints []int
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lineInts, err := SplitInts(line, i+1, sep)
+		if err != nil {
+			return nil, err
+		}
+		ints = append(ints, lineInts...)
+	}
+	return ints, nil
+}
+
+// SplitInts parses integers out of a single line, separated by sep, or by
+// whitespace if sep is empty. The line number is used for error reporting.
+func SplitInts(line string, lineNum int, sep string) ([]int, error) {
+	var ints []int
+	for _, f := range split(line, sep) {
+		n, err := strconv.Atoi(f.text)
+		if err != nil {
+			return nil, errorf(lineNum, f.col, "invalid number %q", f.text)
+		}
+		ints = append(ints, n)
+	}
+	return ints, nil
+}
+
+// field is a piece of a line along with its 1-based column.
+type field struct {
+	text string
+	col  int
+}
+
+// split breaks a line into trimmed fields, separated by sep, or by
+// whitespace if sep is empty, keeping track of where each field starts.
+func split(line, sep string) []field {
+	var fields []field
+	if sep == "" {
+		start := -1
+		for i, ch := range line {
+			switch {
+			case unicode.IsSpace(ch) && start >= 0:
+				fields = append(fields, field{line[start:i], start + 1})
+				start = -1
+			case !unicode.IsSpace(ch) && start < 0:
+				start = i
+			}
+		}
+		if start >= 0 {
+			fields = append(fields, field{line[start:], start + 1})
+		}
+		return fields
+	}
+
+	offset := 0
+	for _, part := range strings.Split(line, sep) {
+		trimmed := strings.TrimSpace(part)
+		col := offset + strings.Index(part, trimmed) + 1
+		fields = append(fields, field{trimmed, col})
+		offset += len(part) + len(sep)
+	}
+	return fields
+}
+
+// Paragraphs reads groups of lines separated by one or more blank lines.
+func Paragraphs(r io.Reader) ([][]string, error) {
+	groups, _, err := paragraphs(r)
+	return groups, err
+}
+
+// paragraphs also returns the line number each group starts on.
+func paragraphs(r io.Reader) ([][]string, []int, error) {
+	lines, err := Lines(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		groups  [][]string
+		starts  []int
+		current []string
+	)
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				groups = append(groups, current)
+				current = nil
+			}
+			continue
+		}
+		if len(current) == 0 {
+			starts = append(starts, i+1)
+		}
+		current = append(current, line)
+	}
+
+	// Don't forget the last group.
+	if len(current) > 0 {
+		groups = append(groups, current)
+	}
+	return groups, starts, nil
+}
+
+// Grid reads a rectangle of characters, one row per line. Trailing blank
+// lines are ignored, but rows of differing widths are an error.
+func Grid(r io.Reader) ([][]rune, error) {
+	lines, err := Lines(r)
+	if err != nil {
+		return nil, err
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	grid := make([][]rune, 0, len(lines))
+	for i, line := range lines {
+		row := []rune(line)
+		if i > 0 && len(row) != len(grid[0]) {
+			// Point at where the row should have ended, or did end.
+			col := len(grid[0]) + 1
+			if len(row) < len(grid[0]) {
+				col = len(row) + 1
+			}
+			return nil, errorf(i+1, col, "row is %d wide, but the first row is %d wide", len(row), len(grid[0]))
+		}
+		grid = append(grid, row)
+	}
+	return grid, nil
+}
+
+// Record is a set of key:value pairs.
+type Record map[string]string
+
+// Records reads groups of whitespace separated key:value pairs. Records are
+// separated by blank lines, and may span several lines.
+func Records(r io.Reader) ([]Record, error) {
+	groups, starts, err := paragraphs(r)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]Record, 0, len(groups))
+	for g, group := range groups {
+		rec := make(Record)
+		for l, line := range group {
+			lineNum := starts[g] + l
+			for _, f := range split(line, "") {
+				sep := strings.Index(f.text, ":")
+				if sep < 0 {
+					return nil, errorf(lineNum, f.col, "expected key:value, but got %q", f.text)
+				}
+				key, value := f.text[:sep], f.text[sep+1:]
+				if key == "" {
+					return nil, errorf(lineNum, f.col, "missing key in %q", f.text)
+				}
+				if _, dup := rec[key]; dup {
+					return nil, errorf(lineNum, f.col, "duplicate key %q", key)
+				}
+				rec[key] = value
+			}
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
@@ -0,0 +1,152 @@
+package input
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInts(t *testing.T) {
+	got, err := Ints(strings.NewReader("1721\n979\n\n-366\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []int{1721, 979, -366}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, but got %v", want, got)
+	}
+}
+
+func TestSeparatedInts(t *testing.T) {
+	tt := []struct {
+		name  string
+		parse func(string) ([]int, error)
+		in    string
+		want  []int
+	}{
+		{
+			name:  "comma",
+			parse: func(s string) ([]int, error) { return CommaInts(strings.NewReader(s)) },
+			in:    "0,3, 6\n7\n",
+			want:  []int{0, 3, 6, 7},
+		},
+		{
+			name:  "whitespace",
+			parse: func(s string) ([]int, error) { return FieldInts(strings.NewReader(s)) },
+			in:    "  1 2\t3\n\n4\n",
+			want:  []int{1, 2, 3, 4},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.parse(tc.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("expected %v, but got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestErrorPosition(t *testing.T) {
+	tt := []struct {
+		name      string
+		parse     func(string) error
+		in        string
+		line, col int
+	}{
+		{
+			name:  "ints",
+			parse: func(s string) error { _, err := Ints(strings.NewReader(s)); return err },
+			in:    "1\n2\n  x3\n",
+			line:  3,
+			col:   3,
+		},
+		{
+			name:  "comma ints",
+			parse: func(s string) error { _, err := CommaInts(strings.NewReader(s)); return err },
+			in:    "1,2\n3,4, y,5",
+			line:  2,
+			col:   6,
+		},
+		{
+			name:  "field ints",
+			parse: func(s string) error { _, err := FieldInts(strings.NewReader(s)); return err },
+			in:    "1 22 3z",
+			line:  1,
+			col:   6,
+		},
+		{
+			name:  "ragged grid",
+			parse: func(s string) error { _, err := Grid(strings.NewReader(s)); return err },
+			in:    "..#\n.#.\n#.\n",
+			line:  3,
+			col:   3,
+		},
+		{
+			name:  "record without colon",
+			parse: func(s string) error { _, err := Records(strings.NewReader(s)); return err },
+			in:    "a:1 b:2\n\nc:3\nd:4 e5",
+			line:  4,
+			col:   5,
+		},
+		{
+			name:  "duplicate record key",
+			parse: func(s string) error { _, err := Records(strings.NewReader(s)); return err },
+			in:    "a:1\nb:2 a:3",
+			line:  2,
+			col:   5,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.parse(tc.in)
+			var inputErr *Error
+			if !errors.As(err, &inputErr) {
+				t.Fatalf("expected *Error, but got %v", err)
+			}
+			if inputErr.Line != tc.line || inputErr.Col != tc.col {
+				t.Fatalf("expected error at %d:%d, but got %v", tc.line, tc.col, err)
+			}
+		})
+	}
+}
+
+func TestParagraphs(t *testing.T) {
+	got, err := Paragraphs(strings.NewReader("abc\n\na\nb\nc\n\n\nab\nac\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := [][]string{{"abc"}, {"a", "b", "c"}, {"ab", "ac"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %q, but got %q", want, got)
+	}
+}
+
+func TestGrid(t *testing.T) {
+	got, err := Grid(strings.NewReader("..#\n#..\n\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := [][]rune{[]rune("..#"), []rune("#..")}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %q, but got %q", want, got)
+	}
+}
+
+func TestRecords(t *testing.T) {
+	in := "ecl:gry pid:860033327\nhcl:#fffffd\n\niyr:2013 cid:\n"
+	got, err := Records(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Record{
+		{"ecl": "gry", "pid": "860033327", "hcl": "#fffffd"},
+		{"iyr": "2013", "cid": ""},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, but got %v", want, got)
+	}
+}
@@ -23,7 +23,6 @@ const EnvVarAoCSession = "AOC_SESSION_TOKEN"
 
 func Fetch(client *http.Client, year uint, id uint, sessionCookie string) (Leaderboard, error) {
 	lbURL := fmt.Sprintf("https://adventofcode.com/%d/leaderboard/private/view/%d.json", year, id)
-	fmt.Println("feching from", lbURL)
 	r, err := http.NewRequest(http.MethodGet, lbURL, nil)
 	if err != nil {
 		return Leaderboard{}, err
@@ -0,0 +1,135 @@
+// Package letters is sets of the lowercase letters a to z, held in the bits
+// of an integer, for the puzzles that ask about letters in common.
+package letters
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// Set is a set of the letters a to z. Bit 0 is a.
+type Set uint32
+
+// All is every letter.
+const All Set = 1<<26 - 1
+
+// Of returns the set of the letters in s.
+func Of(s string) (Set, error) {
+	var set Set
+	for _, r := range s {
+		if r < 'a' || r > 'z' {
+			return 0, fmt.Errorf("%q is not a letter from a to z", r)
+		}
+		set = set.Add(r)
+	}
+	return set, nil
+}
+
+// Add returns the set with r added. r must be from a to z.
+func (s Set) Add(r rune) Set {
+	return s | 1<<(r-'a')
+}
+
+// Has reports whether r is in the set.
+func (s Set) Has(r rune) bool {
+	return r >= 'a' && r <= 'z' && s&(1<<(r-'a')) != 0
+}
+
+// Len returns the number of letters in the set.
+func (s Set) Len() int {
+	return bits.OnesCount32(uint32(s))
+}
+
+// Union returns the letters in either set.
+func (s Set) Union(t Set) Set {
+	return s | t
+}
+
+// Intersect returns the letters in both sets.
+func (s Set) Intersect(t Set) Set {
+	return s & t
+}
+
+// SymmetricDifference returns the letters in one set or the other, but not
+// both.
+func (s Set) SymmetricDifference(t Set) Set {
+	return s ^ t
+}
+
+// String returns the letters in the set, in order.
+func (s Set) String() string {
+	var b strings.Builder
+	for r := 'a'; r <= 'z'; r++ {
+		if s.Has(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Union returns the letters in any of the sets.
+func Union(sets ...Set) Set {
+	var u Set
+	for _, s := range sets {
+		u |= s
+	}
+	return u
+}
+
+// Intersection returns the letters in every one of the sets, or none if
+// there are no sets.
+func Intersection(sets ...Set) Set {
+	if len(sets) == 0 {
+		return 0
+	}
+	i := All
+	for _, s := range sets {
+		i &= s
+	}
+	return i
+}
+
+// SymmetricDifference returns the letters in an odd number of the sets.
+func SymmetricDifference(sets ...Set) Set {
+	var d Set
+	for _, s := range sets {
+		d ^= s
+	}
+	return d
+}
+
+// counts returns how many of the sets hold each letter.
+func counts(sets []Set) [26]int {
+	var n [26]int
+	for _, s := range sets {
+		for i := range n {
+			if s&(1<<i) != 0 {
+				n[i]++
+			}
+		}
+	}
+	return n
+}
+
+// AtLeast returns the letters in k or more of the sets.
+func AtLeast(k int, sets ...Set) Set {
+	var s Set
+	for i, n := range counts(sets) {
+		if n >= k && n > 0 {
+			s |= 1 << i
+		}
+	}
+	return s
+}
+
+// Exactly returns the letters in exactly k of the sets.
+func Exactly(k int, sets ...Set) Set {
+	var s Set
+	for i, n := range counts(sets) {
+		if n == k && n > 0 {
+			s |= 1 << i
+		}
+	}
+	return s
+}
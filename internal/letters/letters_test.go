@@ -0,0 +1,55 @@
+package letters
+
+import "testing"
+
+func mustOf(t *testing.T, words ...string) []Set {
+	t.Helper()
+	sets := make([]Set, len(words))
+	for i, w := range words {
+		s, err := Of(w)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		sets[i] = s
+	}
+	return sets
+}
+
+func TestOf(t *testing.T) {
+	s := mustOf(t, "zebra")[0]
+	if s.String() != "aberz" || s.Len() != 5 || !s.Has('z') || s.Has('c') || s.Has('A') {
+		t.Errorf("unexpected set %q", s)
+	}
+	if _, err := Of("abC"); err == nil {
+		t.Error("expected an error for an uppercase letter")
+	}
+	if All.Len() != 26 {
+		t.Errorf("expected 26 letters in All, but got %d", All.Len())
+	}
+}
+
+func TestOperators(t *testing.T) {
+	sets := mustOf(t, "abc", "abd", "ae")
+	tt := []struct {
+		name string
+		got  Set
+		want string
+	}{
+		{name: "union", got: Union(sets...), want: "abcde"},
+		{name: "intersection", got: Intersection(sets...), want: "a"},
+		{name: "intersection of none", got: Intersection(), want: ""},
+		{name: "at least 2", got: AtLeast(2, sets...), want: "ab"},
+		{name: "at least 0", got: AtLeast(0, sets...), want: "abcde"},
+		{name: "exactly 1", got: Exactly(1, sets...), want: "cde"},
+		{name: "exactly 3", got: Exactly(3, sets...), want: "a"},
+		{name: "symmetric difference", got: SymmetricDifference(sets...), want: "acde"},
+		{name: "pair symmetric difference", got: sets[0].SymmetricDifference(sets[1]), want: "cd"},
+		{name: "pair union", got: sets[0].Union(sets[2]), want: "abce"},
+		{name: "pair intersection", got: sets[1].Intersect(sets[2]), want: "a"},
+	}
+	for _, tc := range tt {
+		if tc.got.String() != tc.want {
+			t.Errorf("%s: expected %q, but got %q", tc.name, tc.want, tc.got)
+		}
+	}
+}
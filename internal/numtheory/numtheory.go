@@ -0,0 +1,208 @@
+// Package numtheory has the bits of number theory that keep turning up in
+// puzzles about things lining up: greatest common divisors, modular
+// inverses, and the Chinese remainder theorem.
+//
+// ExtendedGCD, LCM, ModInverse and the CRT functions come in an int64 flavor
+// and a math/big flavor. The int64 ones do their work with big.Int and return
+// ErrOverflow if the answer does not fit, so they never silently wrap around.
+// GCD and Mod are int64 only, since big.Int has GCD and Mod methods of its
+// own.
+package numtheory
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var (
+	// ErrNotCoprime is returned when numbers that must share no common factor
+	// do share one.
+	ErrNotCoprime = errors.New("not coprime")
+
+	// ErrNoSolution is returned when a system of congruences can't all be
+	// satisfied at once.
+	ErrNoSolution = errors.New("no solution")
+
+	// ErrOverflow is returned when an answer does not fit in an int64.
+	ErrOverflow = errors.New("result overflows int64")
+
+	// ErrModulus is returned for a modulus that is zero or negative.
+	ErrModulus = errors.New("modulus must be positive")
+)
+
+// ExtendedGCD returns g, the greatest common divisor of a and b, along with
+// x and y such that a*x + b*y = g. g is never negative.
+func ExtendedGCD(a, b int64) (g, x, y int64) {
+	gb, xb, yb := ExtendedGCDBig(big.NewInt(a), big.NewInt(b))
+	return gb.Int64(), xb.Int64(), yb.Int64()
+}
+
+// ExtendedGCDBig is ExtendedGCD for big.Int.
+func ExtendedGCDBig(a, b *big.Int) (g, x, y *big.Int) {
+	// big.Int.GCD only takes non-negative inputs, so work on absolute values
+	// and fix up the signs of the coefficients afterward.
+	x, y = new(big.Int), new(big.Int)
+	g = new(big.Int).GCD(x, y, new(big.Int).Abs(a), new(big.Int).Abs(b))
+	if a.Sign() < 0 {
+		x.Neg(x)
+	}
+	if b.Sign() < 0 {
+		y.Neg(y)
+	}
+	return g, x, y
+}
+
+// GCD returns the greatest common divisor of a and b.
+func GCD(a, b int64) int64 {
+	g, _, _ := ExtendedGCD(a, b)
+	return g
+}
+
+// LCM returns the least common multiple of a and b.
+func LCM(a, b int64) (int64, error) {
+	return toInt64(LCMBig(big.NewInt(a), big.NewInt(b)))
+}
+
+// LCMBig is LCM for big.Int.
+func LCMBig(a, b *big.Int) *big.Int {
+	if a.Sign() == 0 || b.Sign() == 0 {
+		return new(big.Int)
+	}
+	g, _, _ := ExtendedGCDBig(a, b)
+	l := new(big.Int).Quo(a, g)
+	l.Mul(l, b)
+	return l.Abs(l)
+}
+
+// Mod returns a modulo m, always in the range [0, m), unlike Go's %
+// operator, which keeps the sign of a. It returns ErrModulus if m isn't
+// positive.
+func Mod(a, m int64) (int64, error) {
+	if m <= 0 {
+		return 0, fmt.Errorf("%d: %w", m, ErrModulus)
+	}
+	return ((a % m) + m) % m, nil
+}
+
+// ModInverse returns x in [0, m) such that a*x = 1 (mod m). It returns
+// ErrNotCoprime if there is no such x, because a and m share a factor.
+func ModInverse(a, m int64) (int64, error) {
+	x, err := ModInverseBig(big.NewInt(a), big.NewInt(m))
+	if err != nil {
+		return 0, err
+	}
+	return x.Int64(), nil
+}
+
+// ModInverseBig is ModInverse for big.Int.
+func ModInverseBig(a, m *big.Int) (*big.Int, error) {
+	if m.Sign() <= 0 {
+		return nil, fmt.Errorf("%v: %w", m, ErrModulus)
+	}
+	g, x, _ := ExtendedGCDBig(a, m)
+	if g.Cmp(big.NewInt(1)) != 0 {
+		return nil, fmt.Errorf("%v and %v: %w", a, m, ErrNotCoprime)
+	}
+	return x.Mod(x, m), nil
+}
+
+// CRT returns the smallest non-negative x such that x = rems[i] (mod
+// mods[i]) for every i. The moduli must be pairwise coprime; if they aren't,
+// ErrNotCoprime is returned. Remainders may be negative or larger than their
+// modulus.
+func CRT(rems, mods []int64) (int64, error) {
+	x, err := CRTBig(bigs(rems), bigs(mods))
+	if err != nil {
+		return 0, err
+	}
+	return toInt64(x)
+}
+
+// CRTBig is CRT for big.Int.
+func CRTBig(rems, mods []*big.Int) (*big.Int, error) {
+	x, _, err := solve(rems, mods, true)
+	return x, err
+}
+
+// GeneralizedCRT is CRT for moduli that need not be coprime. It returns the
+// smallest non-negative solution x along with the least common multiple of
+// the moduli, since every x + k*lcm is also a solution. If the congruences
+// contradict each other, it returns ErrNoSolution.
+func GeneralizedCRT(rems, mods []int64) (x, lcm int64, err error) {
+	xb, lb, err := GeneralizedCRTBig(bigs(rems), bigs(mods))
+	if err != nil {
+		return 0, 0, err
+	}
+	if x, err = toInt64(xb); err != nil {
+		return 0, 0, err
+	}
+	if lcm, err = toInt64(lb); err != nil {
+		return 0, 0, err
+	}
+	return x, lcm, nil
+}
+
+// GeneralizedCRTBig is GeneralizedCRT for big.Int.
+func GeneralizedCRTBig(rems, mods []*big.Int) (x, lcm *big.Int, err error) {
+	return solve(rems, mods, false)
+}
+
+// solve merges the congruences one at a time. Merging x = r1 (mod m1) with
+// x = r2 (mod m2) needs r1 and r2 to agree modulo g = gcd(m1, m2); then
+// x = r1 + m1*k, where k solves (m1/g)*k = (r2-r1)/g (mod m2/g).
+func solve(rems, mods []*big.Int, coprime bool) (*big.Int, *big.Int, error) {
+	if len(rems) != len(mods) {
+		return nil, nil, fmt.Errorf("got %d remainders but %d moduli", len(rems), len(mods))
+	}
+	var (
+		x   = new(big.Int)
+		lcm = big.NewInt(1)
+		one = big.NewInt(1)
+	)
+	for i, m := range mods {
+		if m.Sign() <= 0 {
+			return nil, nil, fmt.Errorf("modulus %d is %v: %w", i, m, ErrModulus)
+		}
+		r := new(big.Int).Mod(rems[i], m)
+
+		g, _, _ := ExtendedGCDBig(lcm, m)
+		if coprime && g.Cmp(one) != 0 {
+			return nil, nil, fmt.Errorf("modulus %v: %w", m, ErrNotCoprime)
+		}
+		diff := new(big.Int).Sub(r, x)
+		if new(big.Int).Rem(diff, g).Sign() != 0 {
+			return nil, nil, fmt.Errorf("x = %v (mod %v) and x = %v (mod %v): %w", x, lcm, r, m, ErrNoSolution)
+		}
+
+		mg := new(big.Int).Quo(m, g)
+		inv, err := ModInverseBig(new(big.Int).Quo(lcm, g), mg)
+		if err != nil {
+			// Can't happen: lcm/g and m/g are coprime by construction.
+			return nil, nil, err
+		}
+		k := diff.Quo(diff, g)
+		k.Mul(k, inv)
+		k.Mod(k, mg)
+
+		x.Add(x, k.Mul(k, lcm))
+		lcm.Mul(lcm, mg)
+		x.Mod(x, lcm)
+	}
+	return x, lcm, nil
+}
+
+func bigs(ns []int64) []*big.Int {
+	b := make([]*big.Int, len(ns))
+	for i, n := range ns {
+		b[i] = big.NewInt(n)
+	}
+	return b
+}
+
+func toInt64(n *big.Int) (int64, error) {
+	if !n.IsInt64() {
+		return 0, fmt.Errorf("%v: %w", n, ErrOverflow)
+	}
+	return n.Int64(), nil
+}
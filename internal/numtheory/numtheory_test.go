@@ -0,0 +1,177 @@
+package numtheory
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestExtendedGCD(t *testing.T) {
+	tt := []struct {
+		a, b, g int64
+	}{
+		{a: 240, b: 46, g: 2},
+		{a: 46, b: 240, g: 2},
+		{a: -240, b: 46, g: 2},
+		{a: 240, b: -46, g: 2},
+		{a: 17, b: 5, g: 1},
+		{a: 0, b: 7, g: 7},
+		{a: 7, b: 0, g: 7},
+	}
+	for _, tc := range tt {
+		t.Run(fmt.Sprintf("%d,%d", tc.a, tc.b), func(t *testing.T) {
+			g, x, y := ExtendedGCD(tc.a, tc.b)
+			if g != tc.g {
+				t.Fatalf("expected gcd %d, but got %d", tc.g, g)
+			}
+			if got := tc.a*x + tc.b*y; got != g {
+				t.Fatalf("expected %d*%d + %d*%d = %d, but got %d", tc.a, x, tc.b, y, g, got)
+			}
+		})
+	}
+}
+
+func TestModInverse(t *testing.T) {
+	tt := []struct {
+		a, m    int64
+		want    int64
+		wantErr error
+	}{
+		{a: 3, m: 11, want: 4},
+		{a: 10, m: 17, want: 12},
+		{a: -3, m: 11, want: 7},
+		{a: 5, m: 1, want: 0},
+		{a: 6, m: 9, wantErr: ErrNotCoprime},
+	}
+	for _, tc := range tt {
+		t.Run(fmt.Sprintf("%d mod %d", tc.a, tc.m), func(t *testing.T) {
+			got, err := ModInverse(tc.a, tc.m)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expected error %v, but got %v", tc.wantErr, err)
+			}
+			if err == nil && got != tc.want {
+				t.Fatalf("expected %d, but got %d", tc.want, got)
+			}
+		})
+	}
+	if _, err := ModInverse(3, 0); !errors.Is(err, ErrModulus) {
+		t.Fatalf("expected ErrModulus for zero modulus, but got %v", err)
+	}
+}
+
+func TestMod(t *testing.T) {
+	tt := []struct {
+		a, m    int64
+		want    int64
+		wantErr error
+	}{
+		{a: 7, m: 3, want: 1},
+		{a: -7, m: 3, want: 2},
+		{a: -6, m: 3, want: 0},
+		{a: 5, m: 0, wantErr: ErrModulus},
+		{a: 5, m: -3, wantErr: ErrModulus},
+	}
+	for _, tc := range tt {
+		got, err := Mod(tc.a, tc.m)
+		if !errors.Is(err, tc.wantErr) {
+			t.Fatalf("%d mod %d: expected error %v, but got %v", tc.a, tc.m, tc.wantErr, err)
+		}
+		if err == nil && got != tc.want {
+			t.Errorf("%d mod %d: expected %d, but got %d", tc.a, tc.m, tc.want, got)
+		}
+	}
+}
+
+func TestCRT(t *testing.T) {
+	tt := []struct {
+		name       string
+		rems, mods []int64
+		want       int64
+		wantErr    error
+	}{
+		{name: "textbook", rems: []int64{2, 3, 2}, mods: []int64{3, 5, 7}, want: 23},
+		{name: "unnormalized remainders", rems: []int64{-1, 8, 16}, mods: []int64{3, 5, 7}, want: 23},
+		{name: "single", rems: []int64{4}, mods: []int64{9}, want: 4},
+		{name: "empty", want: 0},
+		{
+			// Bus schedule from 2020 day 13: bus b leaves i minutes after t.
+			name: "bus schedule",
+			rems: []int64{0, -1, -4, -6, -7},
+			mods: []int64{7, 13, 59, 31, 19},
+			want: 1068781,
+		},
+		{name: "not coprime", rems: []int64{1, 3}, mods: []int64{4, 6}, wantErr: ErrNotCoprime},
+		{
+			// Product of these primes overflows int64.
+			name:    "overflow",
+			rems:    []int64{1, 2, 3},
+			mods:    []int64{1000000007, 1000000009, 1000000021},
+			wantErr: ErrOverflow,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := CRT(tc.rems, tc.mods)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expected error %v, but got %v", tc.wantErr, err)
+			}
+			if err == nil && got != tc.want {
+				t.Fatalf("expected %d, but got %d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestCRTBig(t *testing.T) {
+	rems := bigs([]int64{1, 2, 3})
+	mods := bigs([]int64{1000000007, 1000000009, 1000000021})
+	x, err := CRTBig(rems, mods)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, m := range mods {
+		if r := new(big.Int).Mod(x, m); r.Cmp(rems[i]) != 0 {
+			t.Errorf("expected %v mod %v = %v, but got %v", x, m, rems[i], r)
+		}
+	}
+}
+
+func TestGeneralizedCRT(t *testing.T) {
+	tt := []struct {
+		name       string
+		rems, mods []int64
+		x, lcm     int64
+		wantErr    error
+	}{
+		{name: "coprime", rems: []int64{2, 3, 2}, mods: []int64{3, 5, 7}, x: 23, lcm: 105},
+		{name: "shared factor", rems: []int64{1, 3}, mods: []int64{4, 6}, x: 9, lcm: 12},
+		{name: "same modulus", rems: []int64{5, 5}, mods: []int64{8, 8}, x: 5, lcm: 8},
+		{name: "contradiction", rems: []int64{0, 1}, mods: []int64{4, 6}, wantErr: ErrNoSolution},
+		{name: "negative remainders", rems: []int64{-1, -1}, mods: []int64{6, 10}, x: 29, lcm: 30},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			x, lcm, err := GeneralizedCRT(tc.rems, tc.mods)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expected error %v, but got %v", tc.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if x != tc.x || lcm != tc.lcm {
+				t.Fatalf("expected (%d, %d), but got (%d, %d)", tc.x, tc.lcm, x, lcm)
+			}
+		})
+	}
+}
+
+func TestLCM(t *testing.T) {
+	if got, err := LCM(4, 6); err != nil || got != 12 {
+		t.Fatalf("expected 12, but got %d (%v)", got, err)
+	}
+	if _, err := LCM(math.MaxInt64, math.MaxInt64-1); !errors.Is(err, ErrOverflow) {
+		t.Fatalf("expected ErrOverflow, but got %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
+)
+
+// Command is a tool of a day's own, such as a debugger, that runs instead of
+// the solution when its name follows the flags on the command line.
+type Command struct {
+	// Args describes the command's arguments for the usage message.
+	Args string
+
+	// Summary is a one-line description for the usage message.
+	Summary string
+
+	// Run gets the arguments after the command's name.
+	Run func(args []string, stdin io.Reader, stdout io.Writer, log *trace.Logger) error
+}
+
+// runCommand runs the command named by the first of args.
+func (sol Solution) runCommand(args []string, stdin io.Reader, stdout io.Writer, log *trace.Logger) error {
+	cmd, ok := sol.Commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+	if err := cmd.Run(args[1:], stdin, stdout, log); err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	return nil
+}
+
+// usage is the flag package's usage message, followed by the solution's
+// commands, if it has any.
+func (sol Solution) usage() {
+	w := flag.CommandLine.Output()
+	fmt.Fprintf(w, "Usage: %s [flags] [command [args]]\n\nFlags:\n", flag.CommandLine.Name())
+	flag.PrintDefaults()
+	if len(sol.Commands) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(sol.Commands))
+	for name := range sol.Commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(w, "\nCommands:\n")
+	for _, name := range names {
+		cmd := sol.Commands[name]
+		fmt.Fprintf(w, "  %s %s\n    \t%s\n", name, cmd.Args, cmd.Summary)
+	}
+}
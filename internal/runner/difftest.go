@@ -0,0 +1,190 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/answer"
+	"github.com/ianfoo/advent-of-code-2020/internal/difftest"
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
+)
+
+// DefaultGenerateSize is the size of random inputs when none is given.
+const DefaultGenerateSize = 20
+
+type genOptions struct {
+	generate, difftest bool
+	DiffOptions
+}
+
+// DiffOptions control a differential test.
+type DiffOptions struct {
+	// Runs is the number of inputs to generate, each with the seed after
+	// the last, starting at Seed.
+	Runs int
+	Seed int64
+
+	// Size is passed on to the generator.
+	Size int
+
+	// Part is the only part to check, or 0 to check them all.
+	Part int
+}
+
+func (sol Solution) runGenerated(w io.Writer, log *trace.Logger, opts genOptions) error {
+	if sol.Generate == nil {
+		return errors.New("this solution has no input generator")
+	}
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano()
+	}
+	if opts.generate {
+		for _, line := range sol.Generate(rand.New(rand.NewSource(opts.Seed)), opts.Size) {
+			fmt.Fprintln(w, line)
+		}
+		return nil
+	}
+
+	log.Infof("checking %d inputs of size %d, starting with seed %d", opts.Runs, opts.Size, opts.Seed)
+	if f := sol.DiffTest(opts.DiffOptions); f != nil {
+		fmt.Fprintf(w, "%v\n\n%s\n", f, strings.Join(f.Input, "\n"))
+		return errors.New("found a failing input")
+	}
+	fmt.Fprintf(w, "ok: %d inputs\n", opts.Runs)
+	return nil
+}
+
+// Failure is a generated input that made a solution fail.
+type Failure struct {
+	// Seed and Size generate the original input again.
+	Seed int64
+	Size int
+
+	// Part is the part that failed, or 0 if the input couldn't be read.
+	Part    int
+	Problem string
+
+	// Input is the original input, shrunk as far as it would go.
+	Input []string
+}
+
+func (f *Failure) Error() string {
+	return fmt.Sprintf("part %d: %s (seed %d, size %d, shrunk to %d lines)",
+		f.Part, f.Problem, f.Seed, f.Size, len(f.Input))
+}
+
+// DiffTest solves generated inputs with every implementation of every part.
+// It returns the first input that made an implementation fail or disagree
+// with the others, shrunk as far as it will go, or nil if all is well.
+func (sol Solution) DiffTest(opts DiffOptions) *Failure {
+	for i := 0; i < opts.Runs; i++ {
+		seed := opts.Seed + int64(i)
+		lines := sol.Generate(rand.New(rand.NewSource(seed)), opts.Size)
+		p := sol.check(lines, opts.Part)
+		if p == nil {
+			continue
+		}
+		// Only keep smaller inputs that fail the same way, or a disagreement
+		// could shrink into an input that's simply invalid.
+		lines = difftest.Shrink(lines, sol.Shrink, func(c []string) bool {
+			q := sol.check(c, p.part)
+			return q != nil && q.part == p.part && q.impl == p.impl && q.kind == p.kind
+		})
+		if q := sol.check(lines, p.part); q != nil {
+			p = q
+		}
+		return &Failure{Seed: seed, Size: opts.Size, Part: p.part, Problem: p.msg, Input: lines}
+	}
+	return nil
+}
+
+type problem struct {
+	part int
+	impl string
+	kind problemKind
+	msg  string
+}
+
+type problemKind int
+
+const (
+	// All the implementations failed, or the only one did.
+	failed problemKind = iota
+	// Some implementations failed and others didn't.
+	someFailed
+	// The implementations gave different answers.
+	differed
+)
+
+// check solves an input with every implementation and returns the first
+// problem found in part only, or in any part if only is 0.
+func (sol Solution) check(lines []string, only int) *problem {
+	s := NewSession(trace.Discard)
+	err := catch(func() error {
+		return sol.Solve(strings.NewReader(strings.Join(lines, "\n")+"\n"), s)
+	})
+	if err != nil {
+		return &problem{msg: fmt.Sprintf("reading input: %v", err)}
+	}
+	for _, n := range s.Parts() {
+		if only != 0 && n != only {
+			continue
+		}
+		var ok, bad []Result
+		errs := make(map[string]error)
+		for _, name := range s.Impls(n) {
+			var a answer.Answer
+			err := catch(func() (err error) {
+				a, _, err = s.Solve(n, name)
+				return err
+			})
+			switch {
+			case errors.Is(err, ErrSkip):
+			case err != nil:
+				bad = append(bad, Result{Part: n, Impl: name})
+				// Drop the "part N" that Solve adds; the problem says which
+				// part it is.
+				if inner := errors.Unwrap(err); inner != nil {
+					err = inner
+				}
+				errs[name] = err
+			default:
+				ok = append(ok, Result{Part: n, Impl: name, Answer: a})
+			}
+		}
+		switch {
+		case len(ok) > 0 && len(bad) > 0:
+			f := bad[0]
+			return &problem{part: n, impl: f.Impl, kind: someFailed,
+				msg: fmt.Sprintf("%s says %s, but %s fails: %v", ok[0].Impl, ok[0].Answer, f.Impl, errs[f.Impl])}
+		case len(bad) > 0:
+			f := bad[0]
+			return &problem{part: n, impl: f.Impl, kind: failed, msg: fmt.Sprintf("%s: %v", f.Impl, errs[f.Impl])}
+		}
+		if len(ok) == 0 {
+			continue
+		}
+		for _, r := range ok[1:] {
+			if !r.Answer.Equal(ok[0].Answer) {
+				return &problem{part: n, impl: r.Impl, kind: differed,
+					msg: fmt.Sprintf("%s says %s, but %s says %s", ok[0].Impl, ok[0].Answer, r.Impl, r.Answer)}
+			}
+		}
+	}
+	return nil
+}
+
+// catch turns a panic in fn into an error, since random inputs are good at
+// finding the inputs that make solutions index out of range.
+func catch(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn()
+}
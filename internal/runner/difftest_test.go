@@ -0,0 +1,65 @@
+package runner
+
+import (
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/answer"
+	"github.com/ianfoo/advent-of-code-2020/internal/input"
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
+)
+
+func generateInts(rng *rand.Rand, size int) []string {
+	lines := make([]string, size)
+	for i := range lines {
+		lines[i] = strconv.Itoa(rng.Intn(100))
+	}
+	return lines
+}
+
+func toLines(nums []int) []string {
+	lines := make([]string, len(nums))
+	for i, n := range nums {
+		lines[i] = strconv.Itoa(n)
+	}
+	return lines
+}
+
+func TestDiffTest(t *testing.T) {
+	sol := Solution{Year: 2020, Day: 1, Solve: sumAndCount, Generate: generateInts}
+	if f := sol.DiffTest(DiffOptions{Runs: 20, Size: 10, Seed: 1}); f != nil {
+		t.Fatalf("unexpected failure: %v", f)
+	}
+
+	// Skipping the last number is wrong unless it happens to be zero.
+	sol.Solve = func(r io.Reader, s *Session) error {
+		nums, err := input.Ints(r)
+		if err != nil {
+			return err
+		}
+		if err := sumAndCount(strings.NewReader(strings.Join(toLines(nums), "\n")), s); err != nil {
+			return err
+		}
+		s.Impl(1, "off by one", func(log *trace.Logger) (answer.Answer, error) {
+			var sum int
+			for i := 0; i < len(nums)-1; i++ {
+				sum += nums[i]
+			}
+			return answer.Int(sum), nil
+		})
+		return nil
+	}
+	f := sol.DiffTest(DiffOptions{Runs: 20, Size: 10, Seed: 1})
+	if f == nil {
+		t.Fatal("expected a failure")
+	}
+	if f.Part != 1 {
+		t.Errorf("expected part 1 to fail, but got part %d", f.Part)
+	}
+	if len(f.Input) != 1 || f.Input[0] == "0" {
+		t.Errorf("expected a single nonzero number, but got %q", f.Input)
+	}
+}
@@ -0,0 +1,65 @@
+package runner
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/answer"
+)
+
+// Result is an answer to one part of a puzzle, as printed by a solution. Impl
+// and Took are only set when every implementation is run, and are printed
+// between the part number and the answer:
+//
+//	Part 2 [tracker, 6.1s]: 19208
+type Result struct {
+	Part   int           `json:"part"`
+	Impl   string        `json:"impl,omitempty"`
+	Answer answer.Answer `json:"answer"`
+	Took   time.Duration `json:"took,omitempty"`
+}
+
+// FormatResult returns the line printed for a result.
+func FormatResult(r Result) string {
+	if r.Impl == "" {
+		return answer.FormatLine(r.Part, r.Answer)
+	}
+	return fmt.Sprintf("Part %d [%s, %v]: %s", r.Part, r.Impl, r.Took.Round(time.Microsecond), r.Answer)
+}
+
+var resultRegexp = regexp.MustCompile(`^Part (\d+)(?: \[([^,\]]+), ([^\]]+)\])?: (.*)$`)
+
+// ParseResults reads the results printed by a solution, in order. Lines that
+// aren't results are ignored.
+func ParseResults(r io.Reader) ([]Result, error) {
+	var (
+		s       = bufio.NewScanner(r)
+		results []Result
+	)
+	for s.Scan() {
+		m := resultRegexp.FindStringSubmatch(strings.TrimSpace(s.Text()))
+		if m == nil {
+			continue
+		}
+		part, err := strconv.Atoi(m[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid part number %q", m[1])
+		}
+		res := Result{Part: part, Impl: m[2], Answer: answer.Parse(m[4])}
+		if m[3] != "" {
+			if res.Took, err = time.ParseDuration(m[3]); err != nil {
+				return nil, fmt.Errorf("part %d: invalid time %q", part, m[3])
+			}
+		}
+		results = append(results, res)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
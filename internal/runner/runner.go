@@ -0,0 +1,335 @@
+// Package runner is the shared main function for puzzle solutions. It reads
+// the puzzle input from standard input, sets up logging, runs each part of
+// the solution, and prints the answers.
+//
+// Answers go to standard output, one "Part N: answer" line per part.
+// Everything else goes to the logger, which writes to standard error or a
+// file.
+package runner
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/answer"
+	"github.com/ianfoo/advent-of-code-2020/internal/difftest"
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
+)
+
+// Solver reads a puzzle's input and registers the parts of its solution with
+// the session.
+type Solver func(r io.Reader, s *Session) error
+
+// Part solves one part of a puzzle.
+type Part func(log *trace.Logger) (answer.Answer, error)
+
+// MainImpl is the name of the implementation registered with Session.Part.
+const MainImpl = "main"
+
+// ErrSkip is returned by an implementation that won't run on the input it
+// was given, such as a brute force solution faced with the real input.
+var ErrSkip = errors.New("skipped")
+
+type impl struct {
+	name string
+	fn   Part
+}
+
+// Session collects the parts of a solution.
+type Session struct {
+	// Log is for messages about reading the input. Each part gets a logger
+	// of its own.
+	Log *trace.Logger
+
+	parts map[int][]impl
+}
+
+// NewSession returns a session that logs to log.
+func NewSession(log *trace.Logger) *Session {
+	return &Session{Log: log, parts: make(map[int][]impl)}
+}
+
+// Part registers the solution to part n.
+func (s *Session) Part(n int, fn Part) {
+	s.register(n, MainImpl, fn)
+}
+
+// Impl registers an alternative solution to part n, such as a brute force
+// version kept around to check an optimized one.
+func (s *Session) Impl(n int, name string, fn Part) {
+	s.register(n, name, fn)
+}
+
+func (s *Session) register(n int, name string, fn Part) {
+	impls := s.parts[n]
+	for i := range impls {
+		if impls[i].name == name {
+			impls[i].fn = fn
+			return
+		}
+	}
+	// Keep the main implementation first, so the others are checked
+	// against it.
+	if name == MainImpl {
+		impls = append([]impl{{name, fn}}, impls...)
+	} else {
+		impls = append(impls, impl{name, fn})
+	}
+	s.parts[n] = impls
+}
+
+// Parts returns the registered part numbers, in order.
+func (s *Session) Parts() []int {
+	parts := make([]int, 0, len(s.parts))
+	for n := range s.parts {
+		parts = append(parts, n)
+	}
+	sort.Ints(parts)
+	return parts
+}
+
+// Impls returns the names of the implementations of part n, main first and
+// the rest in the order they were registered.
+func (s *Session) Impls(n int) []string {
+	var names []string
+	for _, i := range s.parts[n] {
+		names = append(names, i.name)
+	}
+	return names
+}
+
+// Solve runs the named implementation of part n and returns its answer and
+// how long it took.
+func (s *Session) Solve(n int, name string) (answer.Answer, time.Duration, error) {
+	var fn Part
+	for _, i := range s.parts[n] {
+		if i.name == name {
+			fn = i.fn
+		}
+	}
+	if fn == nil {
+		return answer.Answer{}, 0, fmt.Errorf("part %d has no implementation %q", n, name)
+	}
+	log := s.Log.Part(n)
+	if name != MainImpl {
+		log = log.With(name)
+	}
+	start := time.Now()
+	a, err := fn(log)
+	took := time.Since(start)
+	log.Debugf("took %v", took)
+	if errors.Is(err, ErrSkip) {
+		return answer.Answer{}, took, err
+	}
+	if err != nil {
+		return answer.Answer{}, took, fmt.Errorf("part %d: %w", n, err)
+	}
+	return a, took, nil
+}
+
+// Main runs solve from the command line. Solutions with flags of their own
+// define them on the default flag set before calling Main.
+func Main(year, day int, solve Solver) {
+	Solution{Year: year, Day: day, Solve: solve}.Main()
+}
+
+// Solution is a puzzle's solution, along with the optional extras that help
+// test it.
+type Solution struct {
+	Year, Day int
+	Solve     Solver
+
+	// Generate makes random inputs for the -generate and -difftest flags.
+	Generate difftest.Generator
+
+	// Shrink makes failing generated inputs smaller. It defaults to
+	// removing lines.
+	Shrink difftest.Shrinker
+
+	// Commands are run by name instead of solving the puzzle.
+	Commands map[string]Command
+}
+
+// Main runs the solution from the command line, like the package-level Main.
+func (sol Solution) Main() {
+	var (
+		level      = flag.String("log-level", trace.LevelInfo.String(), "Log messages up to this `level`: error, warn, info, debug or trace.")
+		logFile    = flag.String("log-file", "", "Write log messages to `path` instead of standard error.")
+		traceLimit = flag.Int("trace-limit", trace.DefaultTraceLimit, "Stop writing trace messages after this many; 0 for no limit.")
+		opts       options
+		gen        genOptions
+	)
+	flag.StringVar(&opts.expectFile, "expect", "", "Check answers against the \"Part N: answer\" lines in `path`.")
+	flag.StringVar(&opts.impl, "impl", "", "Use the implementation called `name` for the parts that have one.")
+	flag.BoolVar(&opts.allImpls, "all-impls", false, "Run every implementation of each part, check that they agree, and show how long each took.")
+	flag.BoolVar(&gen.generate, "generate", false, "Print a random input instead of reading one.")
+	flag.BoolVar(&gen.difftest, "difftest", false, "Run every implementation on random inputs, and shrink any input that makes them fail or disagree.")
+	flag.IntVar(&gen.Runs, "runs", 100, "Number of random inputs for -difftest.")
+	flag.IntVar(&gen.Size, "size", DefaultGenerateSize, "Size of random inputs.")
+	flag.Int64Var(&gen.Seed, "seed", 0, "Seed for random inputs; 0 picks one.")
+	flag.IntVar(&gen.Part, "part", 0, "Only check part `n` with -difftest.")
+	flag.Usage = sol.usage
+	flag.Parse()
+
+	log, closeLog, err := newLogger(*level, *logFile, *traceLimit)
+	if err == nil {
+		log = log.With(Prefix(sol.Year, sol.Day))
+		switch {
+		case flag.NArg() > 0:
+			err = sol.runCommand(flag.Args(), os.Stdin, os.Stdout, log)
+		case gen.generate || gen.difftest:
+			err = sol.runGenerated(os.Stdout, log, gen)
+		default:
+			err = run(os.Stdin, os.Stdout, sol.Solve, log, opts)
+		}
+		closeLog()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+type options struct {
+	expectFile string
+	impl       string
+	allImpls   bool
+}
+
+func newLogger(levelName, logFile string, traceLimit int) (*trace.Logger, func(), error) {
+	level, err := trace.ParseLevel(levelName)
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		out      io.Writer = os.Stderr
+		closeLog           = func() {}
+	)
+	if logFile != "" {
+		f, err := os.Create(logFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("opening log file: %w", err)
+		}
+		out = f
+		closeLog = func() { f.Close() }
+	}
+	log := trace.New(out, level)
+	log.SetTraceLimit(traceLimit)
+	return log, closeLog, nil
+}
+
+// run solves every part, printing each answer as soon as it's known, and
+// then checks the answers against the expected answers file, if any.
+func run(r io.Reader, w io.Writer, solve Solver, log *trace.Logger, opts options) error {
+	var expected map[int]answer.Answer
+	if opts.expectFile != "" {
+		f, err := os.Open(opts.expectFile)
+		if err != nil {
+			return fmt.Errorf("reading expected answers: %w", err)
+		}
+		expected, err = answer.ParseLines(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("reading expected answers: %w", err)
+		}
+	}
+
+	s := NewSession(log)
+	if err := solve(r, s); err != nil {
+		return err
+	}
+
+	var (
+		wrong, disagree []int
+		found           = opts.impl == ""
+	)
+	for _, n := range s.Parts() {
+		impls := s.Impls(n)
+		for _, i := range impls {
+			if i == opts.impl {
+				found = true
+			}
+		}
+		if opts.allImpls {
+			ok, err := solveAll(w, s, n, impls, expected[n])
+			if err != nil {
+				return err
+			}
+			if !ok {
+				disagree = append(disagree, n)
+			}
+			continue
+		}
+
+		name := impls[0]
+		for _, i := range impls {
+			if i == opts.impl {
+				name = i
+			}
+		}
+		a, _, err := s.Solve(n, name)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, answer.FormatLine(n, a))
+
+		if want, ok := expected[n]; ok && !want.Equal(a) {
+			log.Part(n).Errorf("expected %s, but got %s", want, a)
+			wrong = append(wrong, n)
+		}
+	}
+	if !found {
+		return fmt.Errorf("no part has an implementation called %q", opts.impl)
+	}
+	if len(disagree) > 0 {
+		return fmt.Errorf("implementations disagree for parts %v", disagree)
+	}
+	if len(wrong) > 0 {
+		return fmt.Errorf("wrong answers for parts %v", wrong)
+	}
+	return nil
+}
+
+// solveAll runs every implementation of part n, printing a result line for
+// each, and reports whether they all agree with each other and with want.
+func solveAll(w io.Writer, s *Session, n int, impls []string, want answer.Answer) (bool, error) {
+	var (
+		log   = s.Log.Part(n)
+		first Result
+		ok    = true
+	)
+	for _, name := range impls {
+		a, took, err := s.Solve(n, name)
+		if errors.Is(err, ErrSkip) {
+			log.Infof("%s: %v", name, err)
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		res := Result{Part: n, Impl: name, Answer: a, Took: took}
+		fmt.Fprintln(w, FormatResult(res))
+
+		switch {
+		case !want.IsZero() && !want.Equal(a):
+			log.Errorf("%s: expected %s, but got %s", name, want, a)
+			ok = false
+		case first.Impl == "":
+			first = res
+		case !first.Answer.Equal(a):
+			log.Errorf("%s says %s, but %s says %s", first.Impl, first.Answer, name, a)
+			ok = false
+		}
+	}
+	return ok, nil
+}
+
+// Prefix is how messages from a day's solution are labeled.
+func Prefix(year, day int) string {
+	return fmt.Sprintf("%d/%02d", year, day)
+}
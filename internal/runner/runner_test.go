@@ -0,0 +1,241 @@
+package runner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/answer"
+	"github.com/ianfoo/advent-of-code-2020/internal/input"
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
+)
+
+// sumAndCount is a tiny solution: part 1 sums the numbers and part 2 counts
+// them.
+func sumAndCount(r io.Reader, s *Session) error {
+	nums, err := input.Ints(r)
+	if err != nil {
+		return err
+	}
+	s.Part(2, func(log *trace.Logger) (answer.Answer, error) {
+		return answer.Int(len(nums)), nil
+	})
+	s.Part(1, func(log *trace.Logger) (answer.Answer, error) {
+		var sum int
+		for _, n := range nums {
+			sum += n
+		}
+		return answer.Int(sum), nil
+	})
+	return nil
+}
+
+func TestRun(t *testing.T) {
+	var out bytes.Buffer
+	err := run(strings.NewReader("1\n2\n3\n"), &out, sumAndCount, trace.Discard, options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "Part 1: 6\nPart 2: 3\n"; out.String() != want {
+		t.Fatalf("expected %q, but got %q", want, out.String())
+	}
+}
+
+func TestRunExpect(t *testing.T) {
+	dir, err := ioutil.TempDir("", "runner")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tt := []struct {
+		name     string
+		expected string
+		wantErr  bool
+	}{
+		{name: "right", expected: "Part 1: 6\nPart 2: 3\n"},
+		{name: "only part 1 known", expected: "Part 1: 6\n"},
+		{name: "wrong", expected: "Part 1: 6\nPart 2: 4\n", wantErr: true},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(dir, "answers.txt")
+			if err := ioutil.WriteFile(path, []byte(tc.expected), 0644); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err := run(strings.NewReader("1\n2\n3\n"), ioutil.Discard, sumAndCount, trace.Discard, options{expectFile: path})
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("expected error: %t, but got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRunPartError(t *testing.T) {
+	boom := errors.New("boom")
+	solve := func(r io.Reader, s *Session) error {
+		s.Part(1, func(log *trace.Logger) (answer.Answer, error) {
+			return answer.Answer{}, boom
+		})
+		return nil
+	}
+	err := run(strings.NewReader(""), ioutil.Discard, solve, trace.Discard, options{})
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected %v, but got %v", boom, err)
+	}
+}
+
+func TestRunAllImpls(t *testing.T) {
+	tt := []struct {
+		name    string
+		part1   int
+		skip    bool
+		want    []Result
+		wantErr bool
+	}{
+		{
+			name:  "agree",
+			part1: 6,
+			want:  []Result{{Part: 1, Impl: MainImpl}, {Part: 1, Impl: "other"}, {Part: 2, Impl: MainImpl}},
+		},
+		{
+			name:    "disagree",
+			part1:   7,
+			want:    []Result{{Part: 1, Impl: MainImpl}, {Part: 1, Impl: "other"}, {Part: 2, Impl: MainImpl}},
+			wantErr: true,
+		},
+		{
+			name: "skipped",
+			skip: true,
+			want: []Result{{Part: 1, Impl: MainImpl}, {Part: 2, Impl: MainImpl}},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			solve := func(r io.Reader, s *Session) error {
+				s.Impl(1, "other", func(log *trace.Logger) (answer.Answer, error) {
+					if tc.skip {
+						return answer.Answer{}, ErrSkip
+					}
+					return answer.Int(tc.part1), nil
+				})
+				return sumAndCount(r, s)
+			}
+			var out bytes.Buffer
+			err := run(strings.NewReader("1\n2\n3\n"), &out, solve, trace.Discard, options{allImpls: true})
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("expected error: %t, but got %v", tc.wantErr, err)
+			}
+			got, err := ParseResults(&out)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("expected %d results, but got %d: %v", len(tc.want), len(got), got)
+			}
+			for i := range got {
+				if got[i].Part != tc.want[i].Part || got[i].Impl != tc.want[i].Impl {
+					t.Errorf("expected part %d %s, but got part %d %s",
+						tc.want[i].Part, tc.want[i].Impl, got[i].Part, got[i].Impl)
+				}
+			}
+		})
+	}
+}
+
+func TestRunImpl(t *testing.T) {
+	solve := func(r io.Reader, s *Session) error {
+		s.Impl(1, "other", func(log *trace.Logger) (answer.Answer, error) {
+			return answer.Int(-1), nil
+		})
+		return sumAndCount(r, s)
+	}
+	var out bytes.Buffer
+	err := run(strings.NewReader("1\n2\n3\n"), &out, solve, trace.Discard, options{impl: "other"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "Part 1: -1\nPart 2: 3\n"; out.String() != want {
+		t.Fatalf("expected %q, but got %q", want, out.String())
+	}
+
+	err = run(strings.NewReader("1\n"), ioutil.Discard, solve, trace.Discard, options{impl: "missing"})
+	if err == nil {
+		t.Fatal("expected an error for a missing implementation")
+	}
+
+	// Every implementation runs anyway, but the named one has to exist.
+	err = run(strings.NewReader("1\n"), ioutil.Discard, solve, trace.Discard, options{impl: "other", allImpls: true})
+	if err == nil || strings.Contains(err.Error(), "no part has") {
+		t.Fatalf("expected only the disagreement in part 1, but got %v", err)
+	}
+	err = run(strings.NewReader("1\n"), ioutil.Discard, solve, trace.Discard, options{impl: "missing", allImpls: true})
+	if err == nil || !strings.Contains(err.Error(), "no part has") {
+		t.Fatalf("expected an error for a missing implementation, but got %v", err)
+	}
+}
+
+func TestParseResults(t *testing.T) {
+	in := "chatter\nPart 1: 866\nPart 2 [brute force, 1.5ms]: LEGJUPRE\n"
+	got, err := ParseResults(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Result{
+		{Part: 1, Answer: answer.Int(866)},
+		{Part: 2, Impl: "brute force", Took: 1500 * time.Microsecond, Answer: answer.Text("LEGJUPRE")},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d results, but got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i].Part != want[i].Part || got[i].Impl != want[i].Impl ||
+			got[i].Took != want[i].Took || !got[i].Answer.Equal(want[i].Answer) {
+			t.Errorf("expected %+v, but got %+v", want[i], got[i])
+		}
+		if line := FormatResult(got[i]); !strings.Contains(in, line+"\n") {
+			t.Errorf("formatted %q, which isn't in the input", line)
+		}
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	sol := Solution{Commands: map[string]Command{
+		"echo": {Run: func(args []string, stdin io.Reader, stdout io.Writer, log *trace.Logger) error {
+			if len(args) == 0 {
+				return errors.New("nothing to echo")
+			}
+			_, err := fmt.Fprintln(stdout, strings.Join(args, " "))
+			return err
+		}},
+	}}
+
+	var out bytes.Buffer
+	if err := sol.runCommand([]string{"echo", "a", "b"}, nil, &out, trace.Discard); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "a b\n"; out.String() != want {
+		t.Fatalf("expected %q, but got %q", want, out.String())
+	}
+
+	tt := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"echo"}, want: "echo: nothing to echo"},
+		{args: []string{"missing"}, want: `unknown command "missing"`},
+	}
+	for _, tc := range tt {
+		err := sol.runCommand(tc.args, nil, ioutil.Discard, trace.Discard)
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("expected error %q, but got %v", tc.want, err)
+		}
+	}
+}
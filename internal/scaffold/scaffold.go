@@ -0,0 +1,152 @@
+// Package scaffold renders a directory of templates into a new puzzle
+// directory.
+//
+// Every file in the template directory produces one file in the target
+// directory. File names are themselves templates, so "{{.DayDir}}.go.tmpl"
+// becomes "day-05.go". Files ending in .tmpl are rendered with the template
+// data and lose the suffix; anything else is copied as-is.
+package scaffold
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// TemplateSuffix marks files that should be rendered rather than copied.
+const TemplateSuffix = ".tmpl"
+
+// Data is what templates have to work with.
+type Data struct {
+	Day, Year int
+
+	// DayPadded is the zero-padded day number, as used in directory names:
+	// "05".
+	DayPadded string
+
+	// DayDir is the name of the puzzle directory: "day-05".
+	DayDir string
+
+	// URL links to the puzzle description, and InputURL to its input.
+	URL, InputURL string
+}
+
+// NewData fills in the derived fields for a puzzle.
+func NewData(year, day int) Data {
+	var (
+		padded = fmt.Sprintf("%02d", day)
+		url    = fmt.Sprintf("https://adventofcode.com/%d/day/%d", year, day)
+	)
+	return Data{
+		Day:       day,
+		Year:      year,
+		DayPadded: padded,
+		DayDir:    "day-" + padded,
+		URL:       url,
+		InputURL:  url + "/input",
+	}
+}
+
+// Result describes what happened to one file.
+type Result struct {
+	Source, Target string
+
+	// Skipped is true if the target already existed and was left alone.
+	Skipped bool
+}
+
+// Render renders every file in templateDir into targetDir, which is created
+// if needed. Existing files are skipped unless force is set. If templateDir
+// is a single file, it is rendered as the puzzle's solution file,
+// DayDir.go.
+func Render(templateDir, targetDir string, data Data, force bool) ([]Result, error) {
+	fi, err := os.Stat(templateDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read template: %w", err)
+	}
+
+	var sources []string
+	if fi.IsDir() {
+		entries, err := ioutil.ReadDir(templateDir)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read template directory: %w", err)
+		}
+		for _, e := range entries {
+			if !e.IsDir() {
+				sources = append(sources, filepath.Join(templateDir, e.Name()))
+			}
+		}
+		sort.Strings(sources)
+	} else {
+		sources = []string{templateDir}
+	}
+
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		return nil, fmt.Errorf("creating destination directory: %w", err)
+	}
+
+	results := make([]Result, 0, len(sources))
+	for _, src := range sources {
+		name, err := targetName(src, data, fi.IsDir())
+		if err != nil {
+			return results, err
+		}
+		res := Result{
+			Source: src,
+			Target: filepath.Join(targetDir, name),
+		}
+		if _, err := os.Stat(res.Target); err == nil && !force {
+			res.Skipped = true
+			results = append(results, res)
+			continue
+		}
+		if err := renderFile(src, res.Target, data); err != nil {
+			return results, err
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+func targetName(src string, data Data, fromDir bool) (string, error) {
+	if !fromDir {
+		return data.DayDir + ".go", nil
+	}
+	base := filepath.Base(src)
+	tmpl, err := template.New("name").Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("bad template file name %q: %w", base, err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("rendering file name %q: %w", base, err)
+	}
+	return strings.TrimSuffix(b.String(), TemplateSuffix), nil
+}
+
+func renderFile(src, dst string, data Data) error {
+	content, err := ioutil.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("cannot read template: %w", err)
+	}
+	if strings.HasSuffix(src, TemplateSuffix) {
+		tmpl, err := template.New(filepath.Base(src)).Parse(string(content))
+		if err != nil {
+			return fmt.Errorf("cannot parse template %s: %w", src, err)
+		}
+		var b bytes.Buffer
+		if err := tmpl.Execute(&b, data); err != nil {
+			return fmt.Errorf("rendering template %s: %w", src, err)
+		}
+		content = b.Bytes()
+	}
+	if err := ioutil.WriteFile(dst, content, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", dst, err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package scaffold
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestRender(t *testing.T) {
+	var (
+		templateDir = t.TempDir()
+		targetDir   = filepath.Join(t.TempDir(), "2020", "day-05")
+		data        = NewData(2020, 5)
+	)
+	writeFile(t, filepath.Join(templateDir, "{{.DayDir}}.go.tmpl"), "// Day {{ .Day }}: {{ .URL }}\n")
+	writeFile(t, filepath.Join(templateDir, "sample-input.txt"), "{{ not rendered }}")
+
+	results, err := Render(templateDir, targetDir, data, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, but got %+v", results)
+	}
+	if got, want := readFile(t, filepath.Join(targetDir, "day-05.go")), "// Day 5: https://adventofcode.com/2020/day/5\n"; got != want {
+		t.Fatalf("expected rendered %q, but got %q", want, got)
+	}
+	if got, want := readFile(t, filepath.Join(targetDir, "sample-input.txt")), "{{ not rendered }}"; got != want {
+		t.Fatalf("expected copied %q, but got %q", want, got)
+	}
+
+	// Without force, a file that has been worked on is left alone, but
+	// missing files are still filled in.
+	writeFile(t, filepath.Join(targetDir, "day-05.go"), "solved")
+	if err := os.Remove(filepath.Join(targetDir, "sample-input.txt")); err != nil {
+		t.Fatal(err)
+	}
+	results, err = Render(templateDir, targetDir, data, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, r := range results {
+		if wantSkipped := filepath.Base(r.Target) == "day-05.go"; r.Skipped != wantSkipped {
+			t.Fatalf("expected only the solution to be skipped, but got %+v", results)
+		}
+	}
+	if got := readFile(t, filepath.Join(targetDir, "day-05.go")); got != "solved" {
+		t.Fatalf("expected existing file to be kept, but got %q", got)
+	}
+
+	if _, err := Render(templateDir, targetDir, data, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := readFile(t, filepath.Join(targetDir, "day-05.go")); got == "solved" {
+		t.Fatal("expected existing file to be overwritten with force")
+	}
+}
@@ -0,0 +1,154 @@
+// Package session validates and stores the Advent of Code session cookie,
+// which is what authenticates every request to the site.
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultBaseURL is where the real site lives.
+	DefaultBaseURL = "https://adventofcode.com"
+
+	// Lifetime is roughly how long the site honors a session cookie after
+	// logging in. The site doesn't tell us the real expiry, so this is only
+	// good for a heads-up.
+	Lifetime = 30 * 24 * time.Hour
+)
+
+// ErrInvalidToken is returned when the site doesn't recognize a token as a
+// logged-in session.
+var ErrInvalidToken = errors.New("session token is not logged in")
+
+// The site shows the logged-in user's name in the header, followed by their
+// star count. Logged out visitors get a [Log In] link instead.
+var userPat = regexp.MustCompile(`<div class="user">([^<]*)`)
+
+// Client checks tokens against the site.
+type Client struct {
+	BaseURL string
+	HTTP    *http.Client
+}
+
+// NewClient returns a Client for the real site.
+func NewClient() Client {
+	return Client{
+		BaseURL: DefaultBaseURL,
+		HTTP:    http.DefaultClient,
+	}
+}
+
+// Validate fetches the site's front page with the given token and returns
+// the name of the logged-in user. ErrInvalidToken is returned if the site
+// treats the request as logged out.
+func (c Client) Validate(token string) (string, error) {
+	if token == "" {
+		return "", ErrInvalidToken
+	}
+	r, err := http.NewRequest(http.MethodGet, c.BaseURL+"/", nil)
+	if err != nil {
+		return "", err
+	}
+	r.AddCookie(&http.Cookie{Name: "session", Value: token})
+
+	resp, err := c.HTTP.Do(r)
+	if err != nil {
+		return "", fmt.Errorf("validating session: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("validating session: unexpected status %s", resp.Status)
+	}
+
+	// The header is near the top of the page, so don't bother reading the
+	// whole thing.
+	page, err := ioutil.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return "", fmt.Errorf("validating session: %w", err)
+	}
+	m := userPat.FindSubmatch(page)
+	if m == nil {
+		return "", ErrInvalidToken
+	}
+	user := strings.TrimSpace(html.UnescapeString(string(m[1])))
+	if user == "" {
+		return "", ErrInvalidToken
+	}
+	return user, nil
+}
+
+// Stored is a validated session token saved to disk by the login command.
+type Stored struct {
+	Token       string    `json:"token"`
+	User        string    `json:"user"`
+	LoggedInAt  time.Time `json:"logged_in_at"`
+	ValidatedAt time.Time `json:"validated_at"`
+}
+
+// Expires returns the approximate time the token will stop working.
+func (s Stored) Expires() time.Time {
+	return s.LoggedInAt.Add(Lifetime)
+}
+
+// Load reads a stored session. A missing file yields an empty Stored and no
+// error.
+func Load(path string) (Stored, error) {
+	b, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Stored{}, nil
+	}
+	if err != nil {
+		return Stored{}, fmt.Errorf("reading session: %w", err)
+	}
+	var s Stored
+	if err := json.Unmarshal(b, &s); err != nil {
+		return Stored{}, fmt.Errorf("decoding session %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Save writes a session where only the current user can read it.
+func Save(path string, s Stored) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("creating session directory: %w", err)
+	}
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that a failed write doesn't wipe out
+	// a working session, and so the token is never world-readable, even for
+	// a moment.
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".session-*")
+	if err != nil {
+		return fmt.Errorf("saving session: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("saving session: %w", err)
+	}
+	if _, err := tmp.Write(append(b, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("saving session: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("saving session: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("saving session: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const goodToken = "53616c7465645f5f"
+
+// fakeSite serves a front page that looks logged in only for goodToken.
+func fakeSite(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := r.Cookie("session")
+		if err != nil || c.Value != goodToken {
+			fmt.Fprint(w, `<header><div><a href="/2020/auth/login">[Log In]</a></div></header>`)
+			return
+		}
+		fmt.Fprint(w, `<header><div class="user">Eric &amp; Co <span class="star-count">30*</span></div></header>`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestValidate(t *testing.T) {
+	srv := fakeSite(t)
+	client := Client{BaseURL: srv.URL, HTTP: srv.Client()}
+
+	tt := []struct {
+		token   string
+		want    string
+		wantErr error
+	}{
+		{token: goodToken, want: "Eric & Co"},
+		{token: "expired", wantErr: ErrInvalidToken},
+		{token: "", wantErr: ErrInvalidToken},
+	}
+	for _, tc := range tt {
+		t.Run(fmt.Sprintf("token %q", tc.token), func(t *testing.T) {
+			got, err := client.Validate(tc.token)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expected error %v, but got %v", tc.wantErr, err)
+			}
+			if got != tc.want {
+				t.Fatalf("expected user %q, but got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	var (
+		path = filepath.Join(t.TempDir(), "aoc", "session.json")
+		now  = time.Date(2020, 12, 1, 5, 0, 0, 0, time.UTC)
+		want = Stored{Token: goodToken, User: "Eric", LoggedInAt: now, ValidatedAt: now}
+	)
+
+	if got, err := Load(path); err != nil || got != (Stored{}) {
+		t.Fatalf("expected empty session before saving, but got %+v, %v", got, err)
+	}
+	if err := Save(path, want); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if perm := fi.Mode().Perm(); perm != 0600 {
+		t.Fatalf("expected session file mode 0600, but got %o", perm)
+	}
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !got.LoggedInAt.Equal(want.LoggedInAt) || got.Token != want.Token || got.User != want.User {
+		t.Fatalf("expected %+v, but got %+v", want, got)
+	}
+	if exp := got.Expires(); !exp.Equal(now.Add(Lifetime)) {
+		t.Fatalf("expected expiry %v, but got %v", now.Add(Lifetime), exp)
+	}
+}
@@ -0,0 +1,155 @@
+package suite
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Language builds and runs solutions written in one programming language.
+// Solutions read their input from standard input and print "Part N: answer"
+// lines, like the Go ones do.
+type Language struct {
+	Name string
+
+	// Find returns the file that holds the solution in dir, or the
+	// directory itself if the whole thing is the solution, or "" if dir has
+	// no solution in this language.
+	Find func(dir string) (string, error)
+
+	// Prepare builds the solution in file, if it needs building, putting
+	// anything it makes in tmp, and returns the command line that runs
+	// it. Args are only passed on to solutions that understand them.
+	Prepare func(ctx context.Context, file, tmp string, args []string, stderr io.Writer) ([]string, error)
+}
+
+// Languages are the languages that Detect knows about.
+var Languages = []Language{Go, Rust, Python, JavaScript}
+
+// Go solutions are the package in the puzzle directory. They're built before
+// running, so that the time taken doesn't include compiling.
+var Go = Language{
+	Name: "go",
+	Find: func(dir string) (string, error) {
+		files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		for _, f := range files {
+			if !strings.HasSuffix(f, "_test.go") {
+				return dir, err
+			}
+		}
+		return "", err
+	},
+	Prepare: func(ctx context.Context, dir, tmp string, args []string, stderr io.Writer) ([]string, error) {
+		bin := filepath.Join(tmp, "solution")
+		if err := build(ctx, dir, stderr, "go", "build", "-o", bin, "."); err != nil {
+			return nil, err
+		}
+		return append([]string{bin}, args...), nil
+	},
+}
+
+// Rust solutions are either a Cargo project or a lone main.rs.
+var Rust = Language{
+	Name: "rust",
+	Find: func(dir string) (string, error) {
+		for _, name := range []string{"Cargo.toml", "main.rs"} {
+			if ok, err := exists(filepath.Join(dir, name)); ok || err != nil {
+				return filepath.Join(dir, name), err
+			}
+		}
+		return "", nil
+	},
+	Prepare: func(ctx context.Context, file, tmp string, args []string, stderr io.Writer) ([]string, error) {
+		dir := filepath.Dir(file)
+		if filepath.Base(file) == "Cargo.toml" {
+			target := filepath.Join(tmp, "target")
+			if err := build(ctx, dir, stderr, "cargo", "build", "--quiet", "--release", "--target-dir", target); err != nil {
+				return nil, err
+			}
+			return []string{"cargo", "run", "--quiet", "--release", "--target-dir", target}, nil
+		}
+		bin := filepath.Join(tmp, "solution")
+		if err := build(ctx, dir, stderr, "rustc", "-O", "-o", bin, file); err != nil {
+			return nil, err
+		}
+		return []string{bin}, nil
+	},
+}
+
+// Python solutions are main.py, or the only Python file in the directory.
+var Python = Language{
+	Name: "python",
+	Find: findScript("main.py", "*.py"),
+	Prepare: func(ctx context.Context, file, tmp string, args []string, stderr io.Writer) ([]string, error) {
+		return []string{"python3", file}, nil
+	},
+}
+
+// JavaScript solutions are main.js, or the only JavaScript file in the
+// directory, and run with Node.
+var JavaScript = Language{
+	Name: "javascript",
+	Find: findScript("main.js", "*.js"),
+	Prepare: func(ctx context.Context, file, tmp string, args []string, stderr io.Writer) ([]string, error) {
+		return []string{"node", file}, nil
+	},
+}
+
+// Solution is a solution to a puzzle in a particular language.
+type Solution struct {
+	Language Language
+	File     string
+}
+
+// Detect returns the solutions in a puzzle directory.
+func Detect(dir string) ([]Solution, error) {
+	var solutions []Solution
+	for _, lang := range Languages {
+		file, err := lang.Find(dir)
+		if err != nil {
+			return nil, fmt.Errorf("looking for %s solution: %w", lang.Name, err)
+		}
+		if file != "" {
+			solutions = append(solutions, Solution{Language: lang, File: file})
+		}
+	}
+	return solutions, nil
+}
+
+// findScript finds the file called main in a directory, or else the only file
+// matching pattern.
+func findScript(main, pattern string) func(dir string) (string, error) {
+	return func(dir string) (string, error) {
+		if ok, err := exists(filepath.Join(dir, main)); ok || err != nil {
+			return filepath.Join(dir, main), err
+		}
+		files, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil || len(files) != 1 {
+			return "", err
+		}
+		return files[0], nil
+	}
+}
+
+func exists(path string) (bool, error) {
+	_, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func build(ctx context.Context, dir string, stderr io.Writer, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	cmd.Stdout = stderr
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("building: %s: %w", name, err)
+	}
+	return nil
+}
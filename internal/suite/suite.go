@@ -0,0 +1,332 @@
+// Package suite runs puzzle solutions as programs of their own, reads the
+// answers they print, and collects them into one table.
+package suite
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/answer"
+	"github.com/ianfoo/advent-of-code-2020/internal/runner"
+)
+
+// DefaultInput is the name of the input file in a puzzle directory.
+const DefaultInput = "input.txt"
+
+// Puzzle is a directory holding the solution to one day's puzzle.
+type Puzzle struct {
+	Year int    `json:"year"`
+	Day  int    `json:"day"`
+	Dir  string `json:"dir"`
+}
+
+func (p Puzzle) String() string {
+	return runner.Prefix(p.Year, p.Day)
+}
+
+// Find returns the puzzles under root, which holds a directory per year with
+// a "day-NN" directory per day, in order. A year or day of zero matches any.
+func Find(root string, year, day int) ([]Puzzle, error) {
+	years, err := ioutil.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("finding puzzles: %w", err)
+	}
+	var puzzles []Puzzle
+	for _, y := range years {
+		n, err := strconv.Atoi(y.Name())
+		if err != nil || !y.IsDir() || (year != 0 && n != year) {
+			continue
+		}
+		days, err := ioutil.ReadDir(filepath.Join(root, y.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("finding puzzles: %w", err)
+		}
+		for _, d := range days {
+			var dn int
+			if _, err := fmt.Sscanf(d.Name(), "day-%d", &dn); err != nil || !d.IsDir() {
+				continue
+			}
+			if day != 0 && dn != day {
+				continue
+			}
+			puzzles = append(puzzles, Puzzle{
+				Year: n,
+				Day:  dn,
+				Dir:  filepath.Join(root, y.Name(), d.Name()),
+			})
+		}
+	}
+	sort.Slice(puzzles, func(i, j int) bool {
+		if puzzles[i].Year != puzzles[j].Year {
+			return puzzles[i].Year < puzzles[j].Year
+		}
+		return puzzles[i].Day < puzzles[j].Day
+	})
+	if len(puzzles) == 0 {
+		return nil, errors.New("no puzzles found")
+	}
+	return puzzles, nil
+}
+
+// Options control how a puzzle is run.
+type Options struct {
+	// Input is the name of the input file in the puzzle directory.
+	// DefaultInput is used if it's empty.
+	Input string
+
+	// Args are passed to solutions that take flags, such as "-all-impls"
+	// for Go solutions.
+	Args []string
+
+	// Stderr gets what the solution, and anything that builds it, writes
+	// to standard error. It's thrown away if nil.
+	Stderr io.Writer
+}
+
+// Report is what came of running a solution to a puzzle.
+type Report struct {
+	Puzzle   Puzzle          `json:"puzzle"`
+	Language string          `json:"language"`
+	Results  []runner.Result `json:"results"`
+	Took     time.Duration   `json:"took"`
+	Err      error           `json:"-"`
+
+	// Expected holds the recorded answers for the puzzle, if any.
+	Expected map[int]answer.Answer `json:"-"`
+}
+
+// Run builds and runs a solution to a puzzle with its input, and reads its
+// recorded answers to check the results against.
+func Run(ctx context.Context, p Puzzle, sol Solution, opts Options) Report {
+	rep := Report{Puzzle: p, Language: sol.Language.Name}
+	var err error
+	if rep.Expected, err = LoadAnswers(p.Dir); err != nil {
+		rep.Err = err
+		return rep
+	}
+
+	name := opts.Input
+	if name == "" {
+		name = DefaultInput
+	}
+	in, err := os.Open(filepath.Join(p.Dir, name))
+	if err != nil {
+		rep.Err = fmt.Errorf("opening input: %w", err)
+		return rep
+	}
+	defer in.Close()
+
+	tmp, err := ioutil.TempDir("", "aoc-suite")
+	if err != nil {
+		rep.Err = err
+		return rep
+	}
+	defer os.RemoveAll(tmp)
+	// The solution runs in the puzzle directory, so the path to it can't be
+	// relative.
+	file, err := filepath.Abs(sol.File)
+	if err != nil {
+		rep.Err = err
+		return rep
+	}
+	argv, err := sol.Language.Prepare(ctx, file, tmp, opts.Args, opts.Stderr)
+	if err != nil {
+		rep.Err = err
+		return rep
+	}
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Dir = p.Dir
+	cmd.Stdin = in
+	cmd.Stdout = &out
+	cmd.Stderr = opts.Stderr
+
+	start := time.Now()
+	runErr := cmd.Run()
+	rep.Took = time.Since(start)
+
+	// Answers printed before a failure are still worth reporting.
+	rep.Results, err = runner.ParseResults(&out)
+	switch {
+	case runErr != nil:
+		rep.Err = runErr
+	case err != nil:
+		rep.Err = fmt.Errorf("reading answers: %w", err)
+	case len(rep.Results) == 0:
+		rep.Err = errors.New("no answers printed")
+	}
+	return rep
+}
+
+// Wrong returns the parts for which a result doesn't match the recorded
+// answer.
+func (r Report) Wrong() []int {
+	var parts []int
+	for _, res := range r.Results {
+		want, ok := r.Expected[res.Part]
+		if ok && !want.Equal(res.Answer) && (len(parts) == 0 || parts[len(parts)-1] != res.Part) {
+			parts = append(parts, res.Part)
+		}
+	}
+	return parts
+}
+
+// Failed reports whether the solution couldn't run, got an answer wrong, or
+// had implementations that disagreed.
+func (r Report) Failed() bool {
+	return r.Err != nil || len(r.Wrong()) > 0 || len(r.Disagreements()) > 0
+}
+
+// Disagreements returns the parts for which the implementations in the
+// report gave different answers.
+func (r Report) Disagreements() []int {
+	var (
+		first  = make(map[int]answer.Answer)
+		differ = make(map[int]bool)
+		parts  []int
+	)
+	for _, res := range r.Results {
+		f, ok := first[res.Part]
+		if !ok {
+			first[res.Part] = res.Answer
+			continue
+		}
+		if !f.Equal(res.Answer) && !differ[res.Part] {
+			differ[res.Part] = true
+			parts = append(parts, res.Part)
+		}
+	}
+	sort.Ints(parts)
+	return parts
+}
+
+// Row is one line of the results table.
+type Row struct {
+	Puzzle string        `json:"puzzle"`
+	Lang   string        `json:"language"`
+	Part   int           `json:"part,omitempty"`
+	Impl   string        `json:"impl,omitempty"`
+	Answer string        `json:"answer,omitempty"`
+	Took   time.Duration `json:"took,omitempty"`
+	Status string        `json:"status"`
+}
+
+// Rows flattens reports into a table with a row for each answer, and a row
+// for each puzzle that failed.
+func Rows(reports []Report) []Row {
+	var rows []Row
+	for _, rep := range reports {
+		disagree := make(map[int]bool)
+		for _, n := range rep.Disagreements() {
+			disagree[n] = true
+		}
+		for _, res := range rep.Results {
+			want, known := rep.Expected[res.Part]
+			var status string
+			switch {
+			case known && !want.Equal(res.Answer):
+				status = "wrong, want " + want.String()
+			case disagree[res.Part]:
+				status = "differs"
+			case known:
+				status = "ok"
+			default:
+				status = "unchecked"
+			}
+			rows = append(rows, Row{
+				Puzzle: rep.Puzzle.String(),
+				Lang:   rep.Language,
+				Part:   res.Part,
+				Impl:   res.Impl,
+				Answer: res.Answer.String(),
+				Took:   res.Took,
+				Status: status,
+			})
+		}
+		if rep.Err != nil {
+			rows = append(rows, Row{
+				Puzzle: rep.Puzzle.String(),
+				Lang:   rep.Language,
+				Took:   rep.Took,
+				Status: "error: " + strings.TrimSpace(rep.Err.Error()),
+			})
+		}
+	}
+	return rows
+}
+
+// WriteTable writes rows as an aligned text table.
+func WriteTable(w io.Writer, rows []Row) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PUZZLE\tLANG\tPART\tIMPL\tANSWER\tTIME\tSTATUS")
+	for _, r := range rows {
+		var part, took string
+		if r.Part != 0 {
+			part = strconv.Itoa(r.Part)
+		}
+		if r.Took != 0 {
+			took = r.Took.Round(time.Microsecond).String()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.Puzzle, r.Lang, part, r.Impl, r.Answer, took, r.Status)
+	}
+	return tw.Flush()
+}
+
+// AnswersFile is the name of the file in a puzzle directory that records the
+// right answers, as "Part N: answer" lines.
+const AnswersFile = "answers.txt"
+
+// LoadAnswers reads the recorded answers for the puzzle in dir. It returns
+// nil if there aren't any.
+func LoadAnswers(dir string) (map[int]answer.Answer, error) {
+	f, err := os.Open(filepath.Join(dir, AnswersFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading answers: %w", err)
+	}
+	defer f.Close()
+	answers, err := answer.ParseLines(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading answers: %w", err)
+	}
+	return answers, nil
+}
+
+// RecordAnswers writes the answers in a report to the puzzle's answers file,
+// unless it already has one. Only the first answer to each part is recorded.
+// It reports whether the file was written.
+func RecordAnswers(rep Report) (bool, error) {
+	if rep.Expected != nil || rep.Failed() || len(rep.Results) == 0 {
+		return false, nil
+	}
+	var (
+		b    strings.Builder
+		seen = make(map[int]bool)
+	)
+	for _, res := range rep.Results {
+		if !seen[res.Part] {
+			seen[res.Part] = true
+			fmt.Fprintln(&b, answer.FormatLine(res.Part, res.Answer))
+		}
+	}
+	path := filepath.Join(rep.Puzzle.Dir, AnswersFile)
+	if err := ioutil.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return false, fmt.Errorf("recording answers: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,163 @@
+package suite
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/answer"
+	"github.com/ianfoo/advent-of-code-2020/internal/runner"
+)
+
+func TestFind(t *testing.T) {
+	root, err := ioutil.TempDir("", "suite")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(root)
+	for _, dir := range []string{"2020/day-10", "2020/day-02", "2015/day-01", "2020/notes", "templates"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	tt := []struct {
+		name      string
+		year, day int
+		want      []string
+	}{
+		{name: "all", want: []string{"2015/01", "2020/02", "2020/10"}},
+		{name: "year", year: 2020, want: []string{"2020/02", "2020/10"}},
+		{name: "day", year: 2020, day: 10, want: []string{"2020/10"}},
+		{name: "none", year: 2019},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			puzzles, err := Find(root, tc.year, tc.day)
+			if len(tc.want) == 0 {
+				if err == nil {
+					t.Fatalf("expected an error, but got %v", puzzles)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, p := range puzzles {
+				got = append(got, p.String())
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("expected %v, but got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRows(t *testing.T) {
+	rep := Report{
+		Puzzle: Puzzle{Year: 2020, Day: 10},
+		Results: []runner.Result{
+			{Part: 1, Impl: "main", Answer: answer.Int(3000)},
+			{Part: 2, Impl: "main", Answer: answer.Int(8)},
+			{Part: 2, Impl: "dp", Answer: answer.Int(8)},
+			{Part: 2, Impl: "tracker", Answer: answer.Int(9)},
+		},
+		Expected: map[int]answer.Answer{1: answer.Int(3000), 2: answer.Int(8)},
+	}
+	if got := rep.Disagreements(); !reflect.DeepEqual(got, []int{2}) {
+		t.Fatalf("expected disagreement in part 2, but got %v", got)
+	}
+
+	var statuses []string
+	for _, r := range Rows([]Report{rep}) {
+		statuses = append(statuses, r.Status)
+	}
+	if want := []string{"ok", "differs", "differs", "wrong, want 8"}; !reflect.DeepEqual(statuses, want) {
+		t.Fatalf("expected %v, but got %v", want, statuses)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tt := []struct {
+		name  string
+		files []string
+		want  []string
+	}{
+		{name: "go", files: []string{"day-01.go", "day-01_test.go"}, want: []string{"go"}},
+		{name: "go tests only", files: []string{"day-01_test.go"}},
+		{name: "cargo", files: []string{"Cargo.toml", "src/main.rs"}, want: []string{"rust"}},
+		{name: "rustc", files: []string{"main.rs"}, want: []string{"rust"}},
+		{name: "go and python", files: []string{"day-13.go", "crt.py"}, want: []string{"go", "python"}},
+		{name: "python main", files: []string{"main.py", "helpers.py"}, want: []string{"python"}},
+		{name: "ambiguous python", files: []string{"one.py", "two.py"}},
+		{name: "javascript", files: []string{"main.js"}, want: []string{"javascript"}},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "suite")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer os.RemoveAll(dir)
+			for _, f := range tc.files {
+				path := filepath.Join(dir, f)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if err := ioutil.WriteFile(path, nil, 0644); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			solutions, err := Detect(dir)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, s := range solutions {
+				got = append(got, s.Language.Name)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("expected %v, but got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRunPython(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 isn't installed")
+	}
+	dir, err := ioutil.TempDir("", "suite")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"main.py":    "import sys\nnums = [int(l) for l in sys.stdin]\nprint('Part 1:', sum(nums))\nprint('Part 2:', len(nums))\n",
+		DefaultInput: "1\n2\n3\n",
+		AnswersFile:  "Part 1: 6\nPart 2: 4\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	p := Puzzle{Year: 2020, Day: 1, Dir: dir}
+	rep := Run(context.Background(), p, Solution{Language: Python, File: filepath.Join(dir, "main.py")}, Options{})
+	if rep.Err != nil {
+		t.Fatalf("unexpected error: %v", rep.Err)
+	}
+	if len(rep.Results) != 2 || !rep.Results[0].Answer.Equal(answer.Int(6)) {
+		t.Fatalf("unexpected results: %v", rep.Results)
+	}
+	if got := rep.Wrong(); !reflect.DeepEqual(got, []int{2}) {
+		t.Fatalf("expected part 2 to be wrong, but got %v", got)
+	}
+}
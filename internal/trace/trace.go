@@ -0,0 +1,170 @@
+// Package trace is the leveled logger puzzle solutions use to explain what
+// they're doing. It writes somewhere other than standard output, so that
+// the answers printed there stay easy for programs to read.
+package trace
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is how much detail a message goes into. Each level includes the ones
+// before it.
+type Level int
+
+const (
+	LevelError Level = iota
+	LevelWarn
+	LevelInfo
+	LevelDebug
+
+	// LevelTrace is for messages inside loops, which can run to millions of
+	// lines. It is subject to the trace limit.
+	LevelTrace
+
+	// levelOff is below every level, so nothing is written.
+	levelOff Level = -1
+)
+
+var levelNames = []string{"error", "warn", "info", "debug", "trace"}
+
+func (l Level) String() string {
+	if l < 0 || int(l) >= len(levelNames) {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel returns the level with the given name.
+func ParseLevel(s string) (Level, error) {
+	for i, name := range levelNames {
+		if strings.EqualFold(s, name) {
+			return Level(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q (expected one of %s)", s, strings.Join(levelNames, ", "))
+}
+
+// DefaultTraceLimit is how many trace level lines are written before the
+// rest are dropped.
+const DefaultTraceLimit = 10000
+
+// sink is the destination shared by a logger and every logger derived from
+// it with With.
+type sink struct {
+	mu         sync.Mutex
+	w          io.Writer
+	now        func() time.Time
+	traceLimit int
+	traced     int
+}
+
+// Logger writes messages at or below its level, each prefixed with the time,
+// the level, and where it came from.
+type Logger struct {
+	sink   *sink
+	level  Level
+	prefix string
+}
+
+// Discard is a logger that writes nothing. It is handy in tests.
+var Discard = &Logger{sink: &sink{w: ioutil.Discard}, level: levelOff}
+
+// New returns a logger that writes messages at or below level to w.
+func New(w io.Writer, level Level) *Logger {
+	return &Logger{
+		sink:  &sink{w: w, now: time.Now, traceLimit: DefaultTraceLimit},
+		level: level,
+	}
+}
+
+// SetTraceLimit changes how many trace level lines are written, for this
+// logger and every logger sharing its output. Zero means no limit.
+func (l *Logger) SetTraceLimit(n int) {
+	l.sink.mu.Lock()
+	defer l.sink.mu.Unlock()
+	l.sink.traceLimit = n
+}
+
+// With returns a logger that adds prefix to its messages, after any prefix l
+// already has.
+func (l *Logger) With(prefix string) *Logger {
+	if l.prefix != "" {
+		prefix = l.prefix + " " + prefix
+	}
+	return &Logger{sink: l.sink, level: l.level, prefix: prefix}
+}
+
+// Part returns a logger for messages about one part of a puzzle.
+func (l *Logger) Part(n int) *Logger {
+	return l.With(fmt.Sprintf("part %d", n))
+}
+
+// Enabled reports whether messages at level would be written. Use it to skip
+// building expensive messages.
+func (l *Logger) Enabled(level Level) bool {
+	return level <= l.level
+}
+
+// Errorf logs a message about something that went wrong.
+func (l *Logger) Errorf(format string, params ...interface{}) {
+	l.logf(LevelError, format, params...)
+}
+
+// Warnf logs a message about something that looks wrong.
+func (l *Logger) Warnf(format string, params ...interface{}) {
+	l.logf(LevelWarn, format, params...)
+}
+
+// Infof logs a message worth seeing on every run.
+func (l *Logger) Infof(format string, params ...interface{}) {
+	l.logf(LevelInfo, format, params...)
+}
+
+// Debugf logs a message that helps follow a solution's progress.
+func (l *Logger) Debugf(format string, params ...interface{}) {
+	l.logf(LevelDebug, format, params...)
+}
+
+// Tracef logs a message from deep inside a loop.
+func (l *Logger) Tracef(format string, params ...interface{}) {
+	l.logf(LevelTrace, format, params...)
+}
+
+func (l *Logger) logf(level Level, format string, params ...interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+	s := l.sink
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if level == LevelTrace && s.traceLimit > 0 {
+		if s.traced > s.traceLimit {
+			return
+		}
+		s.traced++
+		if s.traced > s.traceLimit {
+			l.write(LevelWarn, fmt.Sprintf("trace limit of %d lines reached; dropping further trace output", s.traceLimit))
+			return
+		}
+	}
+	l.write(level, fmt.Sprintf(format, params...))
+}
+
+// write formats and writes one message. The sink must be locked.
+func (l *Logger) write(level Level, msg string) {
+	var b strings.Builder
+	b.WriteString(l.sink.now().Format("15:04:05.000000"))
+	fmt.Fprintf(&b, " %-5s ", strings.ToUpper(level.String()))
+	if l.prefix != "" {
+		fmt.Fprintf(&b, "[%s] ", l.prefix)
+	}
+	b.WriteString(strings.TrimRight(msg, "\n"))
+	b.WriteByte('\n')
+	io.WriteString(l.sink.w, b.String())
+}
@@ -0,0 +1,87 @@
+package trace
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLogger(level Level) (*Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	l := New(&buf, level)
+	l.sink.now = func() time.Time {
+		return time.Date(2020, 12, 1, 5, 0, 0, 0, time.UTC)
+	}
+	return l, &buf
+}
+
+func TestLevels(t *testing.T) {
+	l, buf := newTestLogger(LevelInfo)
+	l.Errorf("e")
+	l.Warnf("w")
+	l.Infof("i")
+	l.Debugf("d")
+	l.Tracef("t")
+
+	want := "05:00:00.000000 ERROR e\n" +
+		"05:00:00.000000 WARN  w\n" +
+		"05:00:00.000000 INFO  i\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("expected\n%s\nbut got\n%s", want, got)
+	}
+}
+
+func TestPrefix(t *testing.T) {
+	l, buf := newTestLogger(LevelDebug)
+	l.With("2020/12").Part(2).Debugf("heading %d\n", 90)
+
+	want := "05:00:00.000000 DEBUG [2020/12 part 2] heading 90\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("expected %q, but got %q", want, got)
+	}
+}
+
+func TestTraceLimit(t *testing.T) {
+	l, buf := newTestLogger(LevelTrace)
+	l.SetTraceLimit(3)
+	part := l.Part(1)
+	for i := 0; i < 10; i++ {
+		part.Tracef("step %d", i)
+	}
+	l.Infof("done")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected 3 trace lines, a warning and an info line, but got\n%s", buf.String())
+	}
+	if !strings.Contains(lines[3], "WARN") || !strings.Contains(lines[3], "limit of 3") {
+		t.Errorf("expected warning about trace limit, but got %q", lines[3])
+	}
+	if !strings.HasSuffix(lines[4], "done") {
+		t.Errorf("expected messages after the limit to still be written, but got %q", lines[4])
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, name := range []string{"error", "WARN", "Info", "debug", "trace"} {
+		level, err := ParseLevel(name)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			continue
+		}
+		if !strings.EqualFold(level.String(), name) {
+			t.Errorf("expected %s, but got %s", name, level)
+		}
+	}
+	if _, err := ParseLevel("loud"); err == nil {
+		t.Error("expected error for unknown level, but got none")
+	}
+}
+
+func TestDiscard(t *testing.T) {
+	if Discard.Enabled(LevelError) {
+		t.Fatal("expected Discard to have every level disabled")
+	}
+	Discard.Part(1).Errorf("nobody hears this")
+}
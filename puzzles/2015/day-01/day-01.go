@@ -1,19 +1,18 @@
 package main
 
 import (
-	"fmt"
 	"io"
-	"os"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/answer"
+	"github.com/ianfoo/advent-of-code-2020/internal/runner"
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
 )
 
 func main() {
-	if err := run(os.Stdin); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
-	}
+	runner.Main(2015, 1, run)
 }
 
-func run(r io.Reader) error {
+func run(r io.Reader, s *runner.Session) error {
 	var (
 		buf   = make([]byte, 1024)
 		floor int
@@ -41,7 +40,16 @@ func run(r io.Reader) error {
 	if err != nil && err != io.EOF {
 		return err
 	}
-	fmt.Printf("Floor %d\n", floor)
-	fmt.Printf("Entered basement at char %d (1-indexed)\n", basementIndex)
+
+	s.Part(1, func(log *trace.Logger) (answer.Answer, error) {
+		log.Infof("floor %d", floor)
+		return answer.Int(floor), nil
+	})
+
+	s.Part(2, func(log *trace.Logger) (answer.Answer, error) {
+		log.Infof("entered basement at char %d (1-indexed)", basementIndex)
+		return answer.Int(basementIndex), nil
+	})
+
 	return nil
 }
@@ -3,80 +3,78 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
 	"io"
-	"os"
-	"strconv"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/answer"
+	"github.com/ianfoo/advent-of-code-2020/internal/input"
+	"github.com/ianfoo/advent-of-code-2020/internal/runner"
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
 )
 
 const target = 2020
 
 func main() {
-	if err := run(os.Stdin); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-	}
+	runner.Solution{Year: 2020, Day: 1, Solve: run, Generate: generate}.Main()
 }
 
-func run(r io.Reader) error {
-	ints, err := readInput(r)
+func run(r io.Reader, s *runner.Session) error {
+	ints, err := input.Ints(r)
 	if err != nil {
 		return err
 	}
 
-	twoSumTerms, err := findTwoSumTerms(target, ints)
-	if err != nil {
-		return fmt.Errorf("finding two sum terms: %v", err)
-	}
-	{
-		var (
-			x, y = twoSumTerms[0], twoSumTerms[1]
-			sum  = x + y
-			prod = x * y
-		)
-		fmt.Printf("%d + %d = %d; %d x %d = %d\n", x, y, sum, x, y, prod)
-	}
+	s.Part(1, func(log *trace.Logger) (answer.Answer, error) {
+		return part1(ints, findTwoSumTerms, log)
+	})
+	s.Impl(1, "hash", func(log *trace.Logger) (answer.Answer, error) {
+		return part1(ints, findTwoSumTermsHash, log)
+	})
+
+	s.Part(2, func(log *trace.Logger) (answer.Answer, error) {
+		return part2(ints, findThreeSumTerms, log)
+	})
+	s.Impl(2, "hash", func(log *trace.Logger) (answer.Answer, error) {
+		return part2(ints, findThreeSumTermsHash, log)
+	})
 
-	threeSumTerms, err := findThreeSumTerms(target, ints)
-	if err != nil {
-		return fmt.Errorf("finding three sum terms: %v", err)
-	}
-	{
-		var (
-			x, y, z = threeSumTerms[0], threeSumTerms[1], threeSumTerms[2]
-			sum     = x + y + z
-			prod    = x * y * z
-		)
-		fmt.Printf("%d + %d + %d = %d; %d x %d x %d = %d\n", x, y, z, sum, x, y, z, prod)
-	}
 	return nil
 }
 
-func readInput(r io.Reader) ([]int, error) {
+func part1(ints []int, find func(int, []int) ([2]int, error), log *trace.Logger) (answer.Answer, error) {
+	terms, err := find(target, ints)
+	if err != nil {
+		return answer.Answer{}, fmt.Errorf("finding two sum terms: %w", err)
+	}
 	var (
-		s     = bufio.NewScanner(r)
-		input = make([]int, 0)
+		x, y = terms[0], terms[1]
+		sum  = x + y
+		prod = x * y
 	)
-	for s.Scan() {
-		t := s.Text()
-		n, err := strconv.Atoi(t)
-		if err != nil {
-			return nil, fmt.Errorf("could not convert input to number: %v", err)
-		}
-		input = append(input, n)
-	}
-	if err := s.Err(); err != nil {
-		return nil, err
+	log.Infof("%d + %d = %d; %d x %d = %d", x, y, sum, x, y, prod)
+	return answer.Int(prod), nil
+}
+
+func part2(ints []int, find func(int, []int) ([3]int, error), log *trace.Logger) (answer.Answer, error) {
+	terms, err := find(target, ints)
+	if err != nil {
+		return answer.Answer{}, fmt.Errorf("finding three sum terms: %w", err)
 	}
-	return input, nil
+	var (
+		x, y, z = terms[0], terms[1], terms[2]
+		sum     = x + y + z
+		prod    = x * y * z
+	)
+	log.Infof("%d + %d + %d = %d; %d x %d x %d = %d", x, y, z, sum, x, y, z, prod)
+	return answer.Int(prod), nil
 }
 
 // findTwoSumTerms scans the list of input for two terms that add up to the
 // target, shortening its search each time since elements already checked as the
 // first term will be disqualified from consideration.
 func findTwoSumTerms(target int, ints []int) ([2]int, error) {
-	for term1Idx := 0; term1Idx < len(ints)-2; term1Idx++ {
-		for term2Idx := term1Idx + 1; term2Idx < len(ints)-1; term2Idx++ {
+	for term1Idx := 0; term1Idx < len(ints)-1; term1Idx++ {
+		for term2Idx := term1Idx + 1; term2Idx < len(ints); term2Idx++ {
 			if x, y := ints[term1Idx], ints[term2Idx]; x+y == target {
 				return [2]int{x, y}, nil
 			}
@@ -89,9 +87,9 @@ func findTwoSumTerms(target int, ints []int) ([2]int, error) {
 // target, shortening its search each time since elements already checked as the
 // first terms will be disqualified from consideration.
 func findThreeSumTerms(target int, ints []int) ([3]int, error) {
-	for term1Idx := 0; term1Idx < len(ints)-3; term1Idx++ {
-		for term2Idx := term1Idx + 1; term2Idx < len(ints)-2; term2Idx++ {
-			for term3Idx := term2Idx + 1; term3Idx < len(ints)-1; term3Idx++ {
+	for term1Idx := 0; term1Idx < len(ints)-2; term1Idx++ {
+		for term2Idx := term1Idx + 1; term2Idx < len(ints)-1; term2Idx++ {
+			for term3Idx := term2Idx + 1; term3Idx < len(ints); term3Idx++ {
 				if x, y, z := ints[term1Idx], ints[term2Idx], ints[term3Idx]; x+y+z == target {
 					return [3]int{x, y, z}, nil
 				}
@@ -100,3 +98,27 @@ func findThreeSumTerms(target int, ints []int) ([3]int, error) {
 	}
 	return [3]int{}, fmt.Errorf("no terms sum to %d", target)
 }
+
+// findTwoSumTermsHash finds the same terms as findTwoSumTerms in one pass, by
+// remembering the terms seen so far and looking up each one's complement.
+func findTwoSumTermsHash(target int, ints []int) ([2]int, error) {
+	seen := make(map[int]bool, len(ints))
+	for _, y := range ints {
+		if x := target - y; seen[x] {
+			return [2]int{x, y}, nil
+		}
+		seen[y] = true
+	}
+	return [2]int{}, fmt.Errorf("no terms sum to %d", target)
+}
+
+// findThreeSumTermsHash fixes each term in turn and finds the other two with
+// findTwoSumTermsHash among the terms after it.
+func findThreeSumTermsHash(target int, ints []int) ([3]int, error) {
+	for i, x := range ints {
+		if terms, err := findTwoSumTermsHash(target-x, ints[i+1:]); err == nil {
+			return [3]int{x, terms[0], terms[1]}, nil
+		}
+	}
+	return [3]int{}, fmt.Errorf("no terms sum to %d", target)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/runner"
+)
+
+func TestFindSumTerms(t *testing.T) {
+	tt := []struct {
+		name       string
+		ints       []int
+		two, three int
+	}{
+		{name: "sample", ints: []int{1721, 979, 366, 299, 675, 1456}, two: 514579, three: 241861950},
+		{name: "last terms", ints: []int{1, 2, 1000, 20, 1000, 1010, 1010}, two: 1020100, three: 20000000},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			for name, find := range map[string]func(int, []int) ([2]int, error){
+				"loops": findTwoSumTerms,
+				"hash":  findTwoSumTermsHash,
+			} {
+				terms, err := find(target, tc.ints)
+				if err != nil {
+					t.Fatalf("%s: unexpected error: %v", name, err)
+				}
+				if got := terms[0] * terms[1]; got != tc.two {
+					t.Errorf("%s: expected %d, but got %d", name, tc.two, got)
+				}
+			}
+			for name, find := range map[string]func(int, []int) ([3]int, error){
+				"loops": findThreeSumTerms,
+				"hash":  findThreeSumTermsHash,
+			} {
+				terms, err := find(target, tc.ints)
+				if err != nil {
+					t.Fatalf("%s: unexpected error: %v", name, err)
+				}
+				if got := terms[0] * terms[1] * terms[2]; got != tc.three {
+					t.Errorf("%s: expected %d, but got %d", name, tc.three, got)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffTest(t *testing.T) {
+	sol := runner.Solution{Solve: run, Generate: generate}
+	if f := sol.DiffTest(runner.DiffOptions{Runs: 50, Size: 20, Seed: 1}); f != nil {
+		t.Fatalf("%v\n%v", f, f.Input)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"math/rand"
+	"strconv"
+)
+
+// generate makes an expense report with exactly one pair and one triple of
+// entries that sum to the target, in random order.
+func generate(rng *rand.Rand, size int) []string {
+	if size < 5 {
+		size = 5
+	}
+	for {
+		x := 1 + rng.Intn(target-1)
+		a := 1 + rng.Intn(target-2)
+		b := 1 + rng.Intn(target-a-1)
+		ints := []int{x, target - x, a, b, target - a - b}
+		for len(ints) < size {
+			ints = append(ints, 1+rng.Intn(target-1))
+		}
+		if countSums(ints, target, 2) != 1 || countSums(ints, target, 3) != 1 {
+			continue
+		}
+		rng.Shuffle(len(ints), func(i, j int) { ints[i], ints[j] = ints[j], ints[i] })
+		lines := make([]string, len(ints))
+		for i, n := range ints {
+			lines[i] = strconv.Itoa(n)
+		}
+		return lines
+	}
+}
+
+// countSums counts the ways of picking n entries that sum to t.
+func countSums(ints []int, t, n int) int {
+	var count int
+	for i, x := range ints {
+		switch {
+		case n == 1 && x == t:
+			count++
+		case n > 1:
+			count += countSums(ints[i+1:], t-x, n-1)
+		}
+	}
+	return count
+}
@@ -4,111 +4,201 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
-	"os"
 	"regexp"
-	"strconv"
 	"strings"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/answer"
+	"github.com/ianfoo/advent-of-code-2020/internal/input"
+	"github.com/ianfoo/advent-of-code-2020/internal/runner"
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
 )
 
 func main() {
-	if err := run(os.Stdin); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-	}
+	runner.Solution{
+		Year:     2020,
+		Day:      2,
+		Solve:    run,
+		Generate: generate,
+		Commands: map[string]runner.Command{
+			"check": {
+				Args:    "[-policy names] [-explain] [-format text|json]",
+				Summary: "Check the passwords read from standard input against the named policies, and say why each passed or failed.",
+				Run:     check,
+			},
+		},
+	}.Main()
 }
 
 // Match password rule and password lines:
 // [num1]-[num2] [char]: [password]
 var ruleAndPasswordRegexp = regexp.MustCompile(`(?P<num1>\d+)-(?P<num2>\d+) (?P<char>\w): (?P<password>\w+)$`)
 
-func run(r io.Reader) error {
-	numOldValidPasswords, numNewValidPasswords, err := countValidPasswords(r)
+func run(r io.Reader, s *runner.Session) error {
+	entries, _, err := readEntries(r)
 	if err != nil {
 		return err
 	}
-	fmt.Printf("Number of valid passwords by old rules: %d\n", numOldValidPasswords)
-	fmt.Printf("Number of valid passwords by new rules: %d\n", numNewValidPasswords)
+
+	s.Part(1, func(log *trace.Logger) (answer.Answer, error) {
+		result, err := countValid(entries, "old", log)
+		return answer.Int(result), err
+	})
+
+	s.Part(2, func(log *trace.Logger) (answer.Answer, error) {
+		result, err := countValid(entries, "new", log)
+		return answer.Int(result), err
+	})
+
 	return nil
 }
 
 // PasswordEntry represents a line in the input
 type PasswordEntry struct {
-	num1, num2 int
-	char       string
-	password   string
+	Num1     int    `aoc:"num1"`
+	Num2     int    `aoc:"num2"`
+	Char     rune   `aoc:"char,char"`
+	Password string `aoc:"password"`
 }
 
-func countValidPasswords(r io.Reader) (int, int, error) {
+func (pe PasswordEntry) String() string {
+	return fmt.Sprintf("%d-%d %c: %s", pe.Num1, pe.Num2, pe.Char, pe.Password)
+}
+
+// readEntries decodes the password entries in r, and returns them with the
+// line number each came from. Blank lines are skipped.
+func readEntries(r io.Reader) ([]PasswordEntry, []int, error) {
+	lines, err := input.Lines(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading lines: %w", err)
+	}
 	var (
-		s                            = bufio.NewScanner(r)
-		validCountOld, validCountNew int
+		d        = input.NewDecoder(ruleAndPasswordRegexp)
+		entries  []PasswordEntry
+		lineNums []int
 	)
-	for s.Scan() {
-		t := s.Text()
-		entry, err := getPasswordEntryForLine(t)
-		if err != nil {
-			return 0, 0, fmt.Errorf("extract password entry for line: %w", err)
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
 		}
-
-		if isValidForOldRules(entry) {
-			validCountOld++
-		}
-		if isValidForNewRules(entry) {
-			validCountNew++
+		var entry PasswordEntry
+		if err := d.Decode(line, i+1, &entry); err != nil {
+			return nil, nil, fmt.Errorf("extract password entries: %w", err)
 		}
+		entries = append(entries, entry)
+		lineNums = append(lineNums, i+1)
+	}
+	return entries, lineNums, nil
+}
+
+// countValid counts the entries whose passwords meet the named policy.
+func countValid(entries []PasswordEntry, policy string, log *trace.Logger) (int, error) {
+	p, err := LookupPolicy(policy)
+	if err != nil {
+		return 0, err
 	}
-	if err := s.Err(); err != nil {
-		return 0, 0, err
+	var count int
+	for i, entry := range entries {
+		v := p.Check(entry)
+		if v.Valid {
+			count++
+		}
+		log.Tracef("[%4d] %v: valid=%t, %s", i+1, entry, v.Valid, v.Reason)
 	}
-	return validCountOld, validCountNew, nil
+	log.Infof("number of valid passwords by %s rules: %d", policy, count)
+	return count, nil
+}
+
+// checkedEntry is an entry and its verdict under each policy, for the JSON
+// report.
+type checkedEntry struct {
+	Line     int                `json:"line"`
+	Entry    string             `json:"entry"`
+	Verdicts map[string]Verdict `json:"verdicts"`
 }
 
-func getPasswordEntryForLine(line string) (PasswordEntry, error) {
-	m := ruleAndPasswordRegexp.FindStringSubmatch(line)
-	if len(m) < 5 {
-		return PasswordEntry{}, fmt.Errorf("invalid input: %q", line)
+// check prints how many passwords meet each policy, and with -explain, why
+// each one passed or failed.
+func check(args []string, stdin io.Reader, stdout io.Writer, log *trace.Logger) error {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	var (
+		names   = fs.String("policy", strings.Join(PolicyNames(), ","), "Comma-separated `names` of the policies to check; a name given twice is checked once.")
+		explain = fs.Bool("explain", false, "Print each line with why it passed or failed.")
+		format  = fs.String("format", "text", "Output `format`: text or json, which always explains.")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("unknown format %q", *format)
 	}
 
-	num1Str, num2Str, char, password := m[1], m[2], m[3], m[4]
-	num1, err := strconv.Atoi(num1Str)
-	if err != nil {
-		return PasswordEntry{}, fmt.Errorf("invalid first number value: %w", err)
+	var (
+		selected []string
+		ps       []Policy
+		seen     = make(map[string]bool)
+	)
+	for _, name := range strings.Split(*names, ",") {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		p, err := LookupPolicy(name)
+		if err != nil {
+			return err
+		}
+		selected = append(selected, name)
+		ps = append(ps, p)
 	}
-	num2, err := strconv.Atoi(num2Str)
+	entries, lineNums, err := readEntries(stdin)
 	if err != nil {
-		return PasswordEntry{}, fmt.Errorf("invalid second number value: %w", err)
+		return err
 	}
 
-	entry := PasswordEntry{
-		num1:     num1,
-		num2:     num2,
-		char:     char,
-		password: password,
+	var (
+		checked = make([]checkedEntry, len(entries))
+		valid   = make(map[string]int)
+	)
+	for i, entry := range entries {
+		checked[i] = checkedEntry{Line: lineNums[i], Entry: entry.String(), Verdicts: make(map[string]Verdict)}
+		for j, p := range ps {
+			v := p.Check(entry)
+			checked[i].Verdicts[selected[j]] = v
+			if v.Valid {
+				valid[selected[j]]++
+			}
+		}
 	}
-	return entry, nil
-}
-
-// isValidForOldRules determines password validity according to old rules,
-// where the password must contain between num1 and num2 instances of the
-// specified char.
-func isValidForOldRules(pe PasswordEntry) bool {
-	count := strings.Count(pe.password, pe.char)
-	isValid := count >= pe.num1 && count <= pe.num2
-	return isValid
-}
 
-// isValidForNewRules determines password validity according to new rules, where
-// the specified character must be in exactly one of the two character locations
-// provided.
-func isValidForNewRules(pe PasswordEntry) bool {
-	// Indices are 1-based in password file, so correct here!
-	index1, index2 := pe.num1-1, pe.num2-1
-
-	inPos1 := pe.password[index1] == pe.char[0]
-	inPos2 := pe.password[index2] == pe.char[0]
+	if *format == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Policies []string       `json:"policies"`
+			Entries  []checkedEntry `json:"entries"`
+			Valid    map[string]int `json:"valid"`
+		}{selected, checked, valid})
+	}
 
-	// Char must be in either position 1 or 2, but not both.
-	isValid := (inPos1 || inPos2) && !(inPos1 && inPos2)
-	return isValid
+	bw := bufio.NewWriter(stdout)
+	if *explain {
+		for _, c := range checked {
+			fmt.Fprintf(bw, "%4d  %s\n", c.Line, c.Entry)
+			for _, name := range selected {
+				v := c.Verdicts[name]
+				result := "fail"
+				if v.Valid {
+					result = "pass"
+				}
+				fmt.Fprintf(bw, "        %s %s: %s\n", result, name, v.Reason)
+			}
+		}
+	}
+	for _, name := range selected {
+		fmt.Fprintf(bw, "%s: %d of %d valid\n", name, valid[name], len(entries))
+	}
+	return bw.Flush()
 }
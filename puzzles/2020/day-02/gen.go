@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// generate makes a list of password policies and passwords, using only a few
+// letters so that policies are met often enough to be interesting.
+func generate(rng *rand.Rand, size int) []string {
+	const letters = "abcde"
+	lines := make([]string, size)
+	for i := range lines {
+		var (
+			min      = 1 + rng.Intn(5)
+			max      = min + rng.Intn(6)
+			char     = letters[rng.Intn(len(letters))]
+			password = make([]byte, 1+rng.Intn(12))
+		)
+		for j := range password {
+			password[j] = letters[rng.Intn(len(letters))]
+		}
+		lines[i] = fmt.Sprintf("%d-%d %c: %s", min, max, char, password)
+	}
+	return lines
+}
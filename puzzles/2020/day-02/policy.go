@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Verdict is whether a password meets a policy, and why.
+type Verdict struct {
+	Valid  bool   `json:"valid"`
+	Reason string `json:"reason"`
+}
+
+// Policy is a way of reading the rule in a password entry.
+type Policy interface {
+	Check(pe PasswordEntry) Verdict
+}
+
+// PolicyFunc is a function that acts as a Policy.
+type PolicyFunc func(pe PasswordEntry) Verdict
+
+// Check calls f.
+func (f PolicyFunc) Check(pe PasswordEntry) Verdict {
+	return f(pe)
+}
+
+// policies are the policies known by name.
+var policies = map[string]Policy{
+	"old": PolicyFunc(oldRules),
+	"new": PolicyFunc(newRules),
+}
+
+// RegisterPolicy makes a policy available by name. It panics if the name is
+// taken.
+func RegisterPolicy(name string, p Policy) {
+	if _, ok := policies[name]; ok {
+		panic(fmt.Sprintf("policy %q registered twice", name))
+	}
+	policies[name] = p
+}
+
+// LookupPolicy returns the policy with the given name.
+func LookupPolicy(name string) (Policy, error) {
+	p, ok := policies[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown policy %q; choose from %s", name, strings.Join(PolicyNames(), ", "))
+	}
+	return p, nil
+}
+
+// PolicyNames returns the names of the registered policies, in order.
+func PolicyNames() []string {
+	names := make([]string, 0, len(policies))
+	for name := range policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// oldRules is the sled rental place's policy, where the password must
+// contain between num1 and num2 instances of the specified char.
+func oldRules(pe PasswordEntry) Verdict {
+	count := strings.Count(pe.Password, string(pe.Char))
+	return Verdict{
+		Valid:  count >= pe.Num1 && count <= pe.Num2,
+		Reason: fmt.Sprintf("has %d %c, want %d to %d", count, pe.Char, pe.Num1, pe.Num2),
+	}
+}
+
+// newRules is the Toboggan Corporate Policy, where the specified character
+// must be in exactly one of the two character locations provided.
+func newRules(pe PasswordEntry) Verdict {
+	var (
+		matches  int
+		describe []string
+	)
+	for _, pos := range []int{pe.Num1, pe.Num2} {
+		// Positions are 1-based in the password file, and a position past
+		// the end of the password can't hold the character.
+		i := pos - 1
+		switch {
+		case i < 0 || i >= len(pe.Password):
+			describe = append(describe, fmt.Sprintf("position %d is past the end", pos))
+		case rune(pe.Password[i]) == pe.Char:
+			matches++
+			describe = append(describe, fmt.Sprintf("position %d is %c", pos, pe.Char))
+		default:
+			describe = append(describe, fmt.Sprintf("position %d is %c, not %c", pos, pe.Password[i], pe.Char))
+		}
+	}
+	return Verdict{
+		Valid:  matches == 1,
+		Reason: strings.Join(describe, "; "),
+	}
+}
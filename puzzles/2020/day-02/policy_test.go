@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPolicies(t *testing.T) {
+	tt := []struct {
+		policy string
+		entry  PasswordEntry
+		valid  bool
+		reason string
+	}{
+		{policy: "old", entry: PasswordEntry{1, 3, 'a', "abcde"}, valid: true, reason: "has 1 a, want 1 to 3"},
+		{policy: "old", entry: PasswordEntry{1, 3, 'b', "cdefg"}, reason: "has 0 b, want 1 to 3"},
+		{policy: "new", entry: PasswordEntry{1, 3, 'a', "abcde"}, valid: true, reason: "position 1 is a; position 3 is c, not a"},
+		{policy: "new", entry: PasswordEntry{2, 9, 'c', "ccccccccc"}, reason: "position 2 is c; position 9 is c"},
+		{policy: "new", entry: PasswordEntry{1, 7, 'a', "abc"}, valid: true, reason: "position 1 is a; position 7 is past the end"},
+	}
+	for _, tc := range tt {
+		p, err := LookupPolicy(tc.policy)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		v := p.Check(tc.entry)
+		if v.Valid != tc.valid || v.Reason != tc.reason {
+			t.Errorf("%s %v: expected %t (%s), but got %t (%s)", tc.policy, tc.entry, tc.valid, tc.reason, v.Valid, v.Reason)
+		}
+	}
+}
+
+func TestRegisterPolicy(t *testing.T) {
+	RegisterPolicy("no-repeats", PolicyFunc(func(pe PasswordEntry) Verdict {
+		for i := 1; i < len(pe.Password); i++ {
+			if pe.Password[i] == pe.Password[i-1] {
+				return Verdict{Reason: "repeats " + pe.Password[i:i+1]}
+			}
+		}
+		return Verdict{Valid: true, Reason: "no repeats"}
+	}))
+	defer delete(policies, "no-repeats")
+
+	p, err := LookupPolicy("no-repeats")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v := p.Check(PasswordEntry{Password: "abba"}); v.Valid || v.Reason != "repeats b" {
+		t.Errorf("expected abba to repeat b, but got %+v", v)
+	}
+	if _, err := LookupPolicy("bogus"); err == nil {
+		t.Error("expected an error for an unknown policy")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	var (
+		in   = strings.NewReader("1-3 a: abcde\n\n2-9 c: ccccccccc\n")
+		out  strings.Builder
+		want = `   1  1-3 a: abcde
+        pass old: has 1 a, want 1 to 3
+   3  2-9 c: ccccccccc
+        pass old: has 9 c, want 2 to 9
+old: 2 of 2 valid
+`
+	)
+	if err := check([]string{"-policy", "old,old", "-explain"}, in, &out, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != want {
+		t.Errorf("expected %q, but got %q", want, out.String())
+	}
+}
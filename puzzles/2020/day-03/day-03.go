@@ -4,72 +4,80 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"io"
 	"os"
+	"strings"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/answer"
+	"github.com/ianfoo/advent-of-code-2020/internal/grid"
+	"github.com/ianfoo/advent-of-code-2020/internal/input"
+	"github.com/ianfoo/advent-of-code-2020/internal/runner"
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
 )
 
 func main() {
-	if err := run(os.Stdin); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
-	}
+	runner.Solution{
+		Year:     2020,
+		Day:      3,
+		Solve:    run,
+		Generate: generate,
+		Commands: map[string]runner.Command{
+			"explore": {
+				Args:    "[-slope right,down]... [-search limit] [-wrap mode] [-map] [-png path]",
+				Summary: "Count the trees on any slopes down the map read from standard input, or find the slope with the fewest, and draw the path.",
+				Run:     explore,
+			},
+		},
+	}.Main()
 }
 
-func run(r io.Reader) error {
-	input, err := readInput(r)
+func run(r io.Reader, s *runner.Session) error {
+	slope, err := readMap(r)
 	if err != nil {
-		return fmt.Errorf("reading input: %w", err)
+		return err
 	}
 
-	{
-		result, err := part1(input)
-		if err != nil {
-			return fmt.Errorf("part 1: %w", err)
-		}
-		fmt.Printf("Part 1: %d\n", result)
-	}
+	// The map repeats to the right as far as needed.
+	slope.Wrap = grid.WrapX
 
-	{
-		result, err := part2(input)
-		if err != nil {
-			return fmt.Errorf("part 2: %w", err)
-		}
-		fmt.Printf("Part 2: %d\n", result)
-	}
+	s.Part(1, func(log *trace.Logger) (answer.Answer, error) {
+		result, err := part1(slope)
+		return answer.Int(result), err
+	})
+
+	s.Part(2, func(log *trace.Logger) (answer.Answer, error) {
+		result, err := part2(slope)
+		return answer.Int(result), err
+	})
 
 	return nil
 }
 
-func readInput(r io.Reader) ([]string, error) {
-	var (
-		s     = bufio.NewScanner(r)
-		input []string
-	)
-	for s.Scan() {
-		line := s.Text()
-		input = append(input, line)
+func readMap(r io.Reader) (*grid.Grid, error) {
+	rows, err := input.Grid(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading map: %w", err)
 	}
-	if err := s.Err(); err != nil {
-		return nil, err
+	m, err := grid.FromRunes(rows)
+	if err != nil {
+		return nil, fmt.Errorf("reading map: %w", err)
 	}
-	return input, nil
+	return m, nil
 }
 
-func part1(input []string) (int, error) {
-	const step = 3
-	var numTrees int
+// countTrees counts the trees hit going from the top left corner to the
+// bottom of the map, moving over and down by the given amounts each step.
+func countTrees(slope *grid.Grid, over, down int) int {
+	return Descend(slope, Slope{Right: over, Down: down}).Trees
+}
 
-	for n, line := range input {
-		offset := (n * step) % len(line)
-		if line[offset] == '#' {
-			numTrees++
-		}
-	}
-	return numTrees, nil
+func part1(slope *grid.Grid) (int, error) {
+	return countTrees(slope, 3, 1), nil
 }
 
-func part2(input []string) (int, error) {
+func part2(slope *grid.Grid) (int, error) {
 	var patterns = []struct {
 		over, down int
 	}{
@@ -81,21 +89,106 @@ func part2(input []string) (int, error) {
 	}
 
 	var product = 1
-
 	for _, pattern := range patterns {
-		numTrees := 0
-		for n, line := range input {
-			// Skip the current line if its index doesn't divide cleanly into
-			// the number of lines we should move down each step.
-			if n%pattern.down != 0 {
-				continue
-			}
-			offset := (n * pattern.over) % len(line)
-			if line[offset] == '#' {
-				numTrees++
-			}
-		}
-		product *= numTrees
+		product *= countTrees(slope, pattern.over, pattern.down)
 	}
 	return product, nil
 }
+
+// slopeList is a flag that can be given more than once.
+type slopeList []Slope
+
+func (l *slopeList) String() string {
+	s := make([]string, len(*l))
+	for i, slope := range *l {
+		s[i] = slope.String()
+	}
+	return strings.Join(s, " ")
+}
+
+func (l *slopeList) Set(s string) error {
+	slope, err := ParseSlope(s)
+	if err != nil {
+		return err
+	}
+	*l = append(*l, slope)
+	return nil
+}
+
+// explore counts the trees on the slopes given, or on the best ones found by
+// searching, and draws the path of the first.
+func explore(args []string, stdin io.Reader, stdout io.Writer, log *trace.Logger) error {
+	fs := flag.NewFlagSet("explore", flag.ContinueOnError)
+	var (
+		slopes   slopeList
+		search   = fs.Int("search", 0, "Try every slope up to `limit` squares right and down, and show the ones that reach the bottom with the fewest trees.")
+		top      = fs.Int("top", 5, "Show this many slopes from -search.")
+		wrapName = fs.String("wrap", "horizontal", "How the map wraps: none, horizontal, vertical or toroidal.")
+		drawMap  = fs.Bool("map", false, "Draw the path of the first slope on the map, with X for trees hit and O for open squares.")
+		pngPath  = fs.String("png", "", "Draw the path of the first slope as a PNG image at `path`.")
+		scale    = fs.Int("scale", 4, "Width in pixels of a square in the -png image.")
+	)
+	fs.Var(&slopes, "slope", "A slope to try, as `right,down`; can be given more than once. The default is 3,1.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %q", fs.Args())
+	}
+	wrap, err := ParseWrap(*wrapName)
+	if err != nil {
+		return err
+	}
+	m, err := readMap(stdin)
+	if err != nil {
+		return err
+	}
+	m.Wrap = wrap
+
+	var paths []Path
+	for _, slope := range slopes {
+		paths = append(paths, Descend(m, slope))
+	}
+	if *search > 0 {
+		found := Search(m, *search)
+		log.Infof("found %d slopes that reach the bottom", len(found))
+		if len(found) > *top {
+			found = found[:*top]
+		}
+		paths = append(paths, found...)
+	}
+	if len(paths) == 0 {
+		paths = append(paths, Descend(m, Slope{Right: 3, Down: 1}))
+	}
+
+	bw := bufio.NewWriter(stdout)
+	product := 1
+	for i, p := range paths {
+		if i < len(slopes) {
+			product *= p.Trees
+		}
+		fmt.Fprintf(bw, "right %d, down %d: %d trees in %d squares\n", p.Slope.Right, p.Slope.Down, p.Trees, len(p.Squares))
+	}
+	if len(slopes) > 1 {
+		fmt.Fprintf(bw, "product of the trees on the given slopes: %d\n", product)
+	}
+	if *drawMap {
+		fmt.Fprintf(bw, "\n%s\n", Overlay(m, paths[0]))
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+
+	if *pngPath == "" {
+		return nil
+	}
+	f, err := os.Create(*pngPath)
+	if err != nil {
+		return err
+	}
+	if err := WritePNG(f, Overlay(m, paths[0]), *scale); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"math/rand"
+	"strings"
+)
+
+// generate makes a map size rows tall and of random width, with a tree in
+// about one square in five.
+func generate(rng *rand.Rand, size int) []string {
+	var (
+		width = 5 + rng.Intn(27)
+		lines = make([]string, size)
+	)
+	for i := range lines {
+		var b strings.Builder
+		for j := 0; j < width; j++ {
+			if rng.Intn(5) == 0 {
+				b.WriteByte('#')
+			} else {
+				b.WriteByte('.')
+			}
+		}
+		lines[i] = b.String()
+	}
+	return lines
+}
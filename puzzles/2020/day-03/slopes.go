@@ -0,0 +1,216 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/grid"
+)
+
+const (
+	tree = '#'
+	hit  = 'X'
+	miss = 'O'
+)
+
+// Slope is how far the toboggan goes each step.
+type Slope struct {
+	Right, Down int
+}
+
+func (s Slope) String() string {
+	return fmt.Sprintf("%d,%d", s.Right, s.Down)
+}
+
+// ParseSlope reads a slope written as "right,down".
+func ParseSlope(s string) (Slope, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return Slope{}, fmt.Errorf("slope %q: expected right,down", s)
+	}
+	right, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return Slope{}, fmt.Errorf("slope %q: %w", s, err)
+	}
+	down, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return Slope{}, fmt.Errorf("slope %q: %w", s, err)
+	}
+	if down < 1 {
+		return Slope{}, fmt.Errorf("slope %q: the toboggan has to go down", s)
+	}
+	return Slope{Right: right, Down: down}, nil
+}
+
+// wrapNames are the names of the ways the map can wrap.
+var wrapNames = map[string]grid.Wrap{
+	"none":       grid.NoWrap,
+	"horizontal": grid.WrapX,
+	"vertical":   grid.WrapY,
+	"toroidal":   grid.WrapBoth,
+}
+
+// ParseWrap reads the name of a way the map can wrap: none, horizontal,
+// vertical or toroidal.
+func ParseWrap(s string) (grid.Wrap, error) {
+	w, ok := wrapNames[s]
+	if !ok {
+		return 0, fmt.Errorf("unknown wrap %q; choose from none, horizontal, vertical or toroidal", s)
+	}
+	return w, nil
+}
+
+// Path is the squares the toboggan passes through on the way down.
+type Path struct {
+	Slope Slope
+
+	// Squares are where the toboggan has been, as it went, without wrapping
+	// applied.
+	Squares []grid.Point
+
+	Trees int
+}
+
+// Descend follows a slope from the top left corner of the map, which wraps
+// the way the map's Wrap says. The run ends when it leaves the map, or
+// comes back to a square it has already been through, which only happens
+// when the map wraps vertically.
+func Descend(m *grid.Grid, slope Slope) Path {
+	var (
+		path = Path{Slope: slope}
+		seen = make(map[grid.Point]bool)
+		step = grid.Point{X: slope.Right, Y: slope.Down}
+	)
+	for p := (grid.Point{}); ; p = p.Add(step) {
+		at, ok := m.Resolve(p)
+		if !ok || seen[at] {
+			return path
+		}
+		seen[at] = true
+		path.Squares = append(path.Squares, p)
+		if square, _ := m.At(p); square == tree {
+			path.Trees++
+		}
+	}
+}
+
+// Search tries every slope going right by 0 to limit squares and down by 1
+// to limit. It returns the paths that get to the bottom of the map, so that
+// a run can't do well just by leaving early through a side, with the fewest
+// trees first. Among paths with as many trees, those with fewer trees per
+// square come first, and then those that pass through more squares.
+func Search(m *grid.Grid, limit int) []Path {
+	var paths []Path
+	for down := 1; down <= limit; down++ {
+		for right := 0; right <= limit; right++ {
+			if p := Descend(m, Slope{Right: right, Down: down}); reachesBottom(m, p) {
+				paths = append(paths, p)
+			}
+		}
+	}
+	sort.SliceStable(paths, func(i, j int) bool {
+		a, b := paths[i], paths[j]
+		if a.Trees != b.Trees {
+			return a.Trees < b.Trees
+		}
+		if x, y := a.Trees*len(b.Squares), b.Trees*len(a.Squares); x != y {
+			return x < y
+		}
+		return len(a.Squares) > len(b.Squares)
+	})
+	return paths
+}
+
+// reachesBottom reports whether a path gets to within a step of the bottom
+// of the map.
+func reachesBottom(m *grid.Grid, p Path) bool {
+	for _, sq := range p.Squares {
+		if at, _ := m.Resolve(sq); at.Y >= m.Height()-p.Slope.Down {
+			return true
+		}
+	}
+	return false
+}
+
+// Overlay returns a copy of the map with the path drawn on it, using X for
+// the trees it hits and O for the open squares. A map that only wraps
+// horizontally is repeated to the side as far as the path goes, as in the
+// puzzle; any other path is drawn on the map as it is.
+func Overlay(m *grid.Grid, path Path) *grid.Grid {
+	var (
+		w             = m.Width()
+		minTile, tile = 0, 0
+	)
+	if m.Wrap == grid.WrapX {
+		for _, p := range path.Squares {
+			t := floorDiv(p.X, w)
+			if t < minTile {
+				minTile = t
+			}
+			if t > tile {
+				tile = t
+			}
+		}
+	}
+
+	out := grid.New(w*(tile-minTile+1), m.Height(), '.')
+	out.Each(func(p grid.Point, _ rune) {
+		r, _ := m.At(grid.Point{X: p.X + minTile*w, Y: p.Y})
+		out.Set(p, r)
+	})
+	for _, p := range path.Squares {
+		at := p
+		if m.Wrap == grid.WrapX {
+			at.X -= minTile * w
+		} else {
+			at, _ = m.Resolve(p)
+		}
+		if r, _ := out.At(at); r == tree {
+			out.Set(at, hit)
+		} else {
+			out.Set(at, miss)
+		}
+	}
+	return out
+}
+
+func floorDiv(a, n int) int {
+	if a < 0 {
+		return -((-a + n - 1) / n)
+	}
+	return a / n
+}
+
+// squareColors are the colors of the squares in an image of a map.
+var squareColors = map[rune]color.RGBA{
+	tree: {R: 0x22, G: 0x8b, B: 0x22, A: 0xff},
+	hit:  {R: 0xdc, G: 0x14, B: 0x3c, A: 0xff},
+	miss: {R: 0x1e, G: 0x90, B: 0xff, A: 0xff},
+}
+
+// WritePNG draws a map as an image, with each square scale pixels wide.
+func WritePNG(w io.Writer, m *grid.Grid, scale int) error {
+	if scale < 1 {
+		return errors.New("scale must be at least 1")
+	}
+	img := image.NewRGBA(image.Rect(0, 0, m.Width()*scale, m.Height()*scale))
+	m.Each(func(p grid.Point, r rune) {
+		c, ok := squareColors[r]
+		if !ok {
+			c = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+		}
+		for y := p.Y * scale; y < (p.Y+1)*scale; y++ {
+			for x := p.X * scale; x < (p.X+1)*scale; x++ {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	})
+	return png.Encode(w, img)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/grid"
+)
+
+// example is the map from the puzzle description.
+const example = `..##.......
+#...#...#..
+.#....#..#.
+..#.#...#.#
+.#...##..#.
+..#.##.....
+.#.#.#....#
+.#........#
+#.##...#...
+#...##....#
+.#..#...#.#`
+
+func exampleMap(t *testing.T, wrap grid.Wrap) *grid.Grid {
+	t.Helper()
+	m, err := grid.FromLines(strings.Split(example, "\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m.Wrap = wrap
+	return m
+}
+
+func TestDescend(t *testing.T) {
+	tt := []struct {
+		wrap    grid.Wrap
+		slope   Slope
+		trees   int
+		squares int
+	}{
+		{wrap: grid.WrapX, slope: Slope{1, 1}, trees: 2, squares: 11},
+		{wrap: grid.WrapX, slope: Slope{3, 1}, trees: 7, squares: 11},
+		{wrap: grid.WrapX, slope: Slope{5, 1}, trees: 3, squares: 11},
+		{wrap: grid.WrapX, slope: Slope{7, 1}, trees: 4, squares: 11},
+		{wrap: grid.WrapX, slope: Slope{1, 2}, trees: 2, squares: 6},
+		{wrap: grid.NoWrap, slope: Slope{3, 1}, trees: 1, squares: 4},
+		{wrap: grid.WrapBoth, slope: Slope{3, 1}, trees: 7, squares: 11},
+		{wrap: grid.WrapBoth, slope: Slope{1, 2}, trees: 3, squares: 11},
+	}
+	for _, tc := range tt {
+		p := Descend(exampleMap(t, tc.wrap), tc.slope)
+		if p.Trees != tc.trees || len(p.Squares) != tc.squares {
+			t.Errorf("%v, wrap %d: expected %d trees in %d squares, but got %d in %d",
+				tc.slope, tc.wrap, tc.trees, tc.squares, p.Trees, len(p.Squares))
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	best := Search(exampleMap(t, grid.WrapX), 3)[0]
+	if want := (Slope{1, 3}); best.Slope != want || best.Trees != 0 {
+		t.Errorf("expected %v with no trees, but got %v with %d", want, best.Slope, best.Trees)
+	}
+
+	// Without wrapping, going right 3 for each row down leaves by the side
+	// well before the bottom.
+	for _, p := range Search(exampleMap(t, grid.NoWrap), 3) {
+		if p.Slope == (Slope{3, 1}) {
+			t.Errorf("expected %v not to reach the bottom, but got %d squares", p.Slope, len(p.Squares))
+		}
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	m := exampleMap(t, grid.WrapX)
+	got := Overlay(m, Descend(m, Slope{3, 1})).String()
+	lines := strings.Split(got, "\n")
+	if len(lines) != 11 || len(lines[0]) != 33 {
+		t.Fatalf("expected 11 rows of 33, but got:\n%s", got)
+	}
+	if want := "..#.#...#O#..#.#...#.#..#.#...#.#"; lines[3] != want {
+		t.Errorf("expected row 3 to be %q, but got %q", want, lines[3])
+	}
+	if lines[2][6] != 'X' {
+		t.Errorf("expected a tree hit at row 2, column 6, but got %q", lines[2])
+	}
+}
@@ -3,223 +3,118 @@
 package main
 
 import (
-	"bufio"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"os"
-	"regexp"
-	"strconv"
-	"strings"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/answer"
+	"github.com/ianfoo/advent-of-code-2020/internal/input"
+	"github.com/ianfoo/advent-of-code-2020/internal/runner"
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
 )
 
+// checker checks passports against the schema in a file, or the puzzle's.
+type checker struct {
+	schemaFile string
+}
+
 func main() {
-	if err := run(os.Stdin); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
-	}
+	c := &checker{}
+	flag.StringVar(&c.schemaFile, "schema", "", "Read the passport schema from the JSON file at `path` instead of using the puzzle's rules.")
+	runner.Solution{
+		Year:     2020,
+		Day:      4,
+		Solve:    c.run,
+		Generate: generate,
+		Commands: map[string]runner.Command{
+			"report": {
+				Args:    "[-format text|json]",
+				Summary: "Check the passports read from standard input, and list why each invalid one failed, with a summary of the reasons.",
+				Run:     c.report,
+			},
+		},
+	}.Main()
 }
 
-func run(r io.Reader) error {
-	input, err := readInput(r)
+func (c *checker) run(r io.Reader, s *runner.Session) error {
+	schema, err := loadSchema(c.schemaFile)
 	if err != nil {
-		return fmt.Errorf("reading input: %w", err)
+		return err
 	}
-
-	passports := buildPassports(input)
-
-	{
-		result, err := part1(passports)
-		if err != nil {
-			return fmt.Errorf("part 1: %w", err)
-		}
-		fmt.Printf("Part 1: %d\n", result)
+	passports, err := input.Records(r)
+	if err != nil {
+		return fmt.Errorf("reading passports: %w", err)
 	}
 
-	{
-		result, err := part2(passports)
-		if err != nil {
-			return fmt.Errorf("part 2: %w", err)
-		}
-		fmt.Printf("Part 2: %d\n", result)
-	}
+	s.Part(1, func(log *trace.Logger) (answer.Answer, error) {
+		return answer.Int(countValid(schema.Presence(), passports, log)), nil
+	})
+
+	s.Part(2, func(log *trace.Logger) (answer.Answer, error) {
+		return answer.Int(countValid(schema, passports, log)), nil
+	})
 
 	return nil
 }
 
-func readInput(r io.Reader) ([]string, error) {
-	var (
-		s     = bufio.NewScanner(r)
-		input []string
-	)
-	for s.Scan() {
-		line := s.Text()
-		input = append(input, line)
+// loadSchema reads the schema in a file, or returns the default one if there
+// is no file.
+func loadSchema(path string) (*Schema, error) {
+	if path == "" {
+		return DefaultSchema(), nil
 	}
-	if err := s.Err(); err != nil {
+	f, err := os.Open(path)
+	if err != nil {
 		return nil, err
 	}
-	return input, nil
+	defer f.Close()
+	return ReadSchema(f)
 }
 
-func buildPassports(input []string) []string {
-	var (
-		current   string
-		passports []string
-	)
-	for _, line := range input {
-		if line != "" {
-			current += " " + line
-			continue
+// countValid counts the passports that pass the schema.
+func countValid(schema *Schema, passports []input.Record, log *trace.Logger) int {
+	results, sum := schema.ValidateAll(passports)
+	for _, r := range results {
+		if !r.Valid() {
+			log.Debugf("[%4d] %v", r.Passport, r.Failures)
 		}
-		current = strings.Trim(current, " ")
-		passports = append(passports, current)
-		current = ""
-	}
-	if current != "" {
-		current = strings.Trim(current, " ")
-		passports = append(passports, current)
 	}
-	return passports
+	return sum.Valid
 }
 
-func part1(input []string) (int, error) {
-	var (
-		requiredFields = []string{
-			"byr",
-			"iyr",
-			"eyr",
-			"hgt",
-			"hcl",
-			"ecl",
-			"pid",
-		}
-		numValid int
-	)
-
-	for _, currentPassport := range input {
-		isValid := true
-		for _, tok := range requiredFields {
-			if !strings.Contains(currentPassport, tok+":") {
-				isValid = false
-				break
-			}
-		}
-		if isValid {
-			numValid++
-		}
+// report prints the failures for each passport, and a summary.
+func (c *checker) report(args []string, stdin io.Reader, stdout io.Writer, log *trace.Logger) error {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	format := fs.String("format", "text", "Output `format`: text or json.")
+	if err := fs.Parse(args); err != nil {
+		return err
 	}
-	return numValid, nil
-}
-
-func part2(input []string) (int, error) {
-	var (
-		validators = map[string]func(string) bool{
-			"byr": validateByr,
-			"iyr": validateIyr,
-			"eyr": validateEyr,
-			"hgt": validateHgt,
-			"hcl": validateHcl,
-			"ecl": validateEcl,
-			"pid": validatePid,
-		}
-
-		numValid int
-	)
-
-	for _, currentPassport := range input {
-		var (
-			numValidFields = 0
-			fields         = strings.Split(currentPassport, " ")
-		)
-		for _, field := range fields {
-			splitField := strings.SplitN(field, ":", 2)
-			var (
-				fieldName  = splitField[0]
-				fieldValue = splitField[1]
-			)
-			validator, ok := validators[fieldName]
-			if !ok {
-				// Ignore unknown fields.
-				continue
-			}
-			if validField := validator(fieldValue); !validField {
-				break
-			}
-			numValidFields++
-		}
-
-		if numValidFields == len(validators) {
-			// If we've made it here, all required fields have been validated.
-			numValid++
-		}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %q", fs.Args())
 	}
-	return numValid, nil
-}
 
-func validateByr(s string) bool {
-	byr, err := strconv.Atoi(s)
+	schema, err := loadSchema(c.schemaFile)
 	if err != nil {
-		return false
+		return err
 	}
-	return byr >= 1920 && byr <= 2002
-}
-
-func validateIyr(s string) bool {
-	iyr, err := strconv.Atoi(s)
+	passports, err := input.Records(stdin)
 	if err != nil {
-		return false
-	}
-	return iyr >= 2010 && iyr <= 2020
-}
-
-func validateEyr(s string) bool {
-	eyr, err := strconv.Atoi(s)
-	if err != nil {
-		return false
-	}
-	return eyr >= 2020 && eyr <= 2030
-}
-
-func validateHgt(s string) bool {
-	if strings.HasSuffix(s, "cm") {
-		s = strings.TrimSuffix(s, "cm")
-		hgt, err := strconv.Atoi(s)
-		if err != nil {
-			return false
-		}
-		return hgt >= 150 && hgt <= 193
-	}
-	if strings.HasSuffix(s, "in") {
-		s = strings.TrimSuffix(s, "in")
-		hgt, err := strconv.Atoi(s)
-		if err != nil {
-			return false
-		}
-		return hgt >= 59 && hgt <= 76
-	}
-	return false
-}
-
-func validateHcl(s string) bool {
-	match, err := regexp.MatchString(`^#[0-9a-f]{6}$`, s)
-	if err != nil {
-		panic("bad regexp in validateHcl")
-	}
-	return match
-}
-
-func validateEcl(s string) bool {
-	match, err := regexp.MatchString(`^(?:amb)|(?:blu)|(?:brn)|(?:gry)|(?:grn)|(?:hzl)|(?:oth)$`, s)
-	if err != nil {
-		panic("bad regexp in validateEcl")
-	}
-	return match
-}
-
-func validatePid(s string) bool {
-	match, err := regexp.MatchString(`^\d{9}$`, s)
-	if err != nil {
-		panic("bad regexp in validatePid")
-	}
-	return match
+		return fmt.Errorf("reading passports: %w", err)
+	}
+	results, sum := schema.ValidateAll(passports)
+
+	switch *format {
+	case "text":
+		return WriteText(stdout, results, sum)
+	case "json":
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Results []Result `json:"results"`
+			Summary Summary  `json:"summary"`
+		}{results, sum})
+	}
+	return fmt.Errorf("unknown format %q", *format)
 }
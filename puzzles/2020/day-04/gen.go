@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// fieldValues makes a value for each passport field, which is only sometimes
+// valid.
+var fieldValues = map[string]func(rng *rand.Rand) string{
+	"byr": func(rng *rand.Rand) string { return fmt.Sprint(1900 + rng.Intn(120)) },
+	"iyr": func(rng *rand.Rand) string { return fmt.Sprint(2000 + rng.Intn(30)) },
+	"eyr": func(rng *rand.Rand) string { return fmt.Sprint(2015 + rng.Intn(20)) },
+	"hgt": func(rng *rand.Rand) string {
+		switch rng.Intn(3) {
+		case 0:
+			return fmt.Sprintf("%dcm", 140+rng.Intn(60))
+		case 1:
+			return fmt.Sprintf("%din", 55+rng.Intn(25))
+		}
+		return fmt.Sprint(100 + rng.Intn(100))
+	},
+	"hcl": func(rng *rand.Rand) string {
+		if rng.Intn(4) == 0 {
+			return fmt.Sprintf("%06x", rng.Intn(1<<24))
+		}
+		return fmt.Sprintf("#%06x", rng.Intn(1<<24))
+	},
+	"ecl": func(rng *rand.Rand) string {
+		colors := []string{"amb", "blu", "brn", "gry", "grn", "hzl", "oth", "xry"}
+		return colors[rng.Intn(len(colors))]
+	},
+	"pid": func(rng *rand.Rand) string {
+		if rng.Intn(4) == 0 {
+			return fmt.Sprint(rng.Intn(1e8))
+		}
+		return fmt.Sprintf("%09d", rng.Intn(1e9))
+	},
+	"cid": func(rng *rand.Rand) string { return fmt.Sprint(100 + rng.Intn(250)) },
+}
+
+// generate makes size passports, each missing a field now and then, with
+// their fields in random order over one to three lines.
+func generate(rng *rand.Rand, size int) []string {
+	names := []string{"byr", "iyr", "eyr", "hgt", "hcl", "ecl", "pid", "cid"}
+	var lines []string
+	for i := 0; i < size; i++ {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		var fields []string
+		for _, name := range names {
+			if rng.Intn(10) == 0 {
+				continue
+			}
+			fields = append(fields, name+":"+fieldValues[name](rng))
+		}
+		rng.Shuffle(len(fields), func(i, j int) { fields[i], fields[j] = fields[j], fields[i] })
+		for len(fields) > 0 {
+			n := 1 + rng.Intn(len(fields))
+			lines = append(lines, strings.Join(fields[:n], " "))
+			fields = fields[n:]
+		}
+	}
+	return lines
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/input"
+)
+
+// Field types.
+const (
+	// TypeAny accepts any value.
+	TypeAny = ""
+
+	// TypeInt is a whole number between Min and Max.
+	TypeInt = "int"
+
+	// TypeHeight is a whole number followed by one of the Units, and between
+	// that unit's Min and Max. When more than one unit ends the value, the
+	// longest is used.
+	TypeHeight = "height"
+
+	// TypeRegex matches Pattern.
+	TypeRegex = "regex"
+
+	// TypeEnum is one of the Values.
+	TypeEnum = "enum"
+)
+
+// Range is the smallest and largest value allowed, inclusive.
+type Range struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// Field says what a passport field looks like.
+type Field struct {
+	Name     string `json:"name"`
+	Required bool   `json:"required,omitempty"`
+	Type     string `json:"type,omitempty"`
+
+	// Range is for TypeInt.
+	*Range `json:",omitempty"`
+
+	// Units are for TypeHeight.
+	Units map[string]Range `json:"units,omitempty"`
+
+	// Pattern is for TypeRegex.
+	Pattern string `json:"pattern,omitempty"`
+
+	// Values are for TypeEnum.
+	Values []string `json:"values,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// Schema is the fields a passport can have.
+type Schema struct {
+	Fields []Field `json:"fields"`
+}
+
+// DefaultSchema returns the rules from the puzzle.
+func DefaultSchema() *Schema {
+	s := &Schema{Fields: []Field{
+		{Name: "byr", Required: true, Type: TypeInt, Range: &Range{Min: 1920, Max: 2002}},
+		{Name: "iyr", Required: true, Type: TypeInt, Range: &Range{Min: 2010, Max: 2020}},
+		{Name: "eyr", Required: true, Type: TypeInt, Range: &Range{Min: 2020, Max: 2030}},
+		{Name: "hgt", Required: true, Type: TypeHeight, Units: map[string]Range{
+			"cm": {Min: 150, Max: 193},
+			"in": {Min: 59, Max: 76},
+		}},
+		{Name: "hcl", Required: true, Type: TypeRegex, Pattern: `^#[0-9a-f]{6}$`},
+		{Name: "ecl", Required: true, Type: TypeEnum, Values: []string{"amb", "blu", "brn", "gry", "grn", "hzl", "oth"}},
+		{Name: "pid", Required: true, Type: TypeRegex, Pattern: `^\d{9}$`},
+		{Name: "cid"},
+	}}
+	if err := s.Compile(); err != nil {
+		panic(fmt.Sprintf("bad default schema: %v", err))
+	}
+	return s
+}
+
+// ReadSchema reads a schema in JSON.
+func ReadSchema(r io.Reader) (*Schema, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parsing schema: %w", err)
+	}
+	if err := s.Compile(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Compile checks that each field has what its type needs, and compiles
+// patterns.
+func (s *Schema) Compile() error {
+	seen := make(map[string]bool)
+	for i := range s.Fields {
+		f := &s.Fields[i]
+		if f.Name == "" {
+			return fmt.Errorf("field %d has no name", i+1)
+		}
+		if seen[f.Name] {
+			return fmt.Errorf("field %q appears twice", f.Name)
+		}
+		seen[f.Name] = true
+
+		switch f.Type {
+		case TypeAny:
+		case TypeInt:
+			if f.Range == nil {
+				return fmt.Errorf("field %q: int needs a min and max", f.Name)
+			}
+		case TypeHeight:
+			if len(f.Units) == 0 {
+				return fmt.Errorf("field %q: height needs units", f.Name)
+			}
+		case TypeRegex:
+			re, err := regexp.Compile(f.Pattern)
+			if err != nil {
+				return fmt.Errorf("field %q: %w", f.Name, err)
+			}
+			f.re = re
+		case TypeEnum:
+			if len(f.Values) == 0 {
+				return fmt.Errorf("field %q: enum needs values", f.Name)
+			}
+		default:
+			return fmt.Errorf("field %q: unknown type %q", f.Name, f.Type)
+		}
+	}
+	return nil
+}
+
+// Presence returns a schema with the same fields, which accepts any value for
+// them.
+func (s *Schema) Presence() *Schema {
+	p := &Schema{Fields: make([]Field, len(s.Fields))}
+	for i, f := range s.Fields {
+		p.Fields[i] = Field{Name: f.Name, Required: f.Required}
+	}
+	return p
+}
+
+// Failure is a reason a passport isn't valid.
+type Failure struct {
+	Field string `json:"field,omitempty"`
+
+	// Problem is the kind of failure, which is the same for every passport
+	// that fails the same way.
+	Problem string `json:"problem"`
+
+	// Detail says what was wrong with this passport's value.
+	Detail string `json:"detail,omitempty"`
+}
+
+func (f Failure) String() string {
+	s := f.Problem
+	if f.Field != "" {
+		s = f.Field + ": " + s
+	}
+	if f.Detail != "" {
+		s += " (" + f.Detail + ")"
+	}
+	return s
+}
+
+// check returns why value isn't right for the field, or nil if it is.
+func (f *Field) check(value string) *Failure {
+	fail := func(problem, format string, args ...interface{}) *Failure {
+		return &Failure{Field: f.Name, Problem: problem, Detail: fmt.Sprintf(format, args...)}
+	}
+	inRange := func(s string, r Range) *Failure {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fail("not a number", "%q", s)
+		}
+		if n < r.Min || n > r.Max {
+			return fail("out of range", "%d is not between %d and %d", n, r.Min, r.Max)
+		}
+		return nil
+	}
+
+	switch f.Type {
+	case TypeInt:
+		return inRange(value, *f.Range)
+	case TypeHeight:
+		var unit string
+		for u := range f.Units {
+			if strings.HasSuffix(value, u) && len(u) > len(unit) {
+				unit = u
+			}
+		}
+		if unit == "" {
+			return fail("no unit", "%q", value)
+		}
+		return inRange(strings.TrimSuffix(value, unit), f.Units[unit])
+	case TypeRegex:
+		if !f.re.MatchString(value) {
+			return fail("doesn't match pattern", "%q", value)
+		}
+	case TypeEnum:
+		for _, v := range f.Values {
+			if value == v {
+				return nil
+			}
+		}
+		return fail("not allowed", "%q", value)
+	}
+	return nil
+}
+
+// Result is how a passport fared.
+type Result struct {
+	// Passport is the passport's place in the batch, starting at 1.
+	Passport int       `json:"passport"`
+	Failures []Failure `json:"failures,omitempty"`
+}
+
+// Valid reports whether the passport passed.
+func (r Result) Valid() bool {
+	return len(r.Failures) == 0
+}
+
+// Validate checks the fields of a passport. Fields that aren't in the schema
+// are allowed.
+func (s *Schema) Validate(n int, passport input.Record) Result {
+	res := Result{Passport: n}
+
+	for i := range s.Fields {
+		f := &s.Fields[i]
+		value, ok := passport[f.Name]
+		if !ok {
+			if f.Required {
+				res.Failures = append(res.Failures, Failure{Field: f.Name, Problem: "missing"})
+			}
+			continue
+		}
+		if fail := f.check(value); fail != nil {
+			res.Failures = append(res.Failures, *fail)
+		}
+	}
+	return res
+}
+
+// Summary counts the passports in a batch, and how often each kind of
+// failure happened.
+type Summary struct {
+	Passports int            `json:"passports"`
+	Valid     int            `json:"valid"`
+	Failures  map[string]int `json:"failures"`
+}
+
+// ValidateAll checks every passport in a batch.
+func (s *Schema) ValidateAll(passports []input.Record) ([]Result, Summary) {
+	var (
+		results = make([]Result, len(passports))
+		sum     = Summary{Passports: len(passports), Failures: make(map[string]int)}
+	)
+	for i, passport := range passports {
+		results[i] = s.Validate(i+1, passport)
+		if results[i].Valid() {
+			sum.Valid++
+		}
+		for _, f := range results[i].Failures {
+			sum.Failures[Failure{Field: f.Field, Problem: f.Problem}.String()]++
+		}
+	}
+	return results, sum
+}
+
+// WriteText writes the failures of each invalid passport, then the summary.
+func WriteText(w io.Writer, results []Result, sum Summary) error {
+	bw := bufio.NewWriter(w)
+	for _, r := range results {
+		if r.Valid() {
+			continue
+		}
+		fmt.Fprintf(bw, "passport %d:\n", r.Passport)
+		for _, f := range r.Failures {
+			fmt.Fprintf(bw, "  %v\n", f)
+		}
+	}
+
+	fmt.Fprintf(bw, "%d of %d passports valid\n", sum.Valid, sum.Passports)
+	reasons := make([]string, 0, len(sum.Failures))
+	for reason := range sum.Failures {
+		reasons = append(reasons, reason)
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		a, b := reasons[i], reasons[j]
+		if sum.Failures[a] != sum.Failures[b] {
+			return sum.Failures[a] > sum.Failures[b]
+		}
+		return a < b
+	})
+	for _, reason := range reasons {
+		fmt.Fprintf(bw, "%6d  %s\n", sum.Failures[reason], reason)
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/input"
+)
+
+func TestReadSchema(t *testing.T) {
+	f, err := os.Open("passport-schema.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+	s, err := ReadSchema(f)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(s, DefaultSchema()) {
+		t.Errorf("expected passport-schema.json to match the default schema, but got %+v", s)
+	}
+
+	tt := []struct {
+		schema string
+		err    string
+	}{
+		{schema: `{"fields": [{"name": "a", "type": "int"}]}`, err: `field "a": int needs a min and max`},
+		{schema: `{"fields": [{"name": "a", "type": "regex", "pattern": "("}]}`, err: `field "a": error parsing regexp`},
+		{schema: `{"fields": [{"name": "a", "type": "date"}]}`, err: `field "a": unknown type "date"`},
+		{schema: `{"fields": [{"name": "a"}, {"name": "a"}]}`, err: `field "a" appears twice`},
+	}
+	for _, tc := range tt {
+		_, err := ReadSchema(strings.NewReader(tc.schema))
+		if err == nil || !strings.HasPrefix(err.Error(), tc.err) {
+			t.Errorf("%s: expected error %q, but got %v", tc.schema, tc.err, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	const valid = "pid:087499704 hgt:74in ecl:grn iyr:2012 eyr:2030 byr:1980 hcl:#623a2f"
+	tt := []struct {
+		name     string
+		passport string
+		presence bool
+		want     []string
+	}{
+		{name: "valid", passport: valid},
+		{name: "cid is optional", passport: valid + " cid:100"},
+		{
+			name:     "field name inside a value",
+			passport: strings.Replace(valid, "byr:1980", "cid:byr:1980", 1),
+			presence: true,
+			want:     []string{"byr: missing"},
+		},
+		{
+			name:     "bad values",
+			passport: "pid:0123456789 hgt:190 ecl:ambx iyr:2009 eyr:abc byr:2003 hcl:#123abz",
+			want: []string{
+				`byr: out of range (2003 is not between 1920 and 2002)`,
+				`iyr: out of range (2009 is not between 2010 and 2020)`,
+				`eyr: not a number ("abc")`,
+				`hgt: no unit ("190")`,
+				`hcl: doesn't match pattern ("#123abz")`,
+				`ecl: not allowed ("ambx")`,
+				`pid: doesn't match pattern ("0123456789")`,
+			},
+		},
+		{
+			name:     "presence only",
+			passport: "pid:1 hgt:tall ecl:grn iyr:2012 eyr:2030 byr:1980 hcl:red",
+			presence: true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			s := DefaultSchema()
+			if tc.presence {
+				s = s.Presence()
+			}
+			passports, err := input.Records(strings.NewReader(tc.passport))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			res := s.Validate(1, passports[0])
+			var got []string
+			for _, f := range res.Failures {
+				got = append(got, f.String())
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expected %q, but got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestHeightUnits(t *testing.T) {
+	s := &Schema{Fields: []Field{{Name: "len", Type: TypeHeight, Units: map[string]Range{
+		"m":  {Min: 1, Max: 2},
+		"mm": {Min: 1000, Max: 2000},
+	}}}}
+	if err := s.Compile(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tt := []struct {
+		value string
+		valid bool
+	}{
+		{value: "2m", valid: true},
+		{value: "1500mm", valid: true},
+		{value: "1500m"},
+		{value: "2mm"},
+	}
+	for _, tc := range tt {
+		if res := s.Validate(1, input.Record{"len": tc.value}); res.Valid() != tc.valid {
+			t.Errorf("%s: expected valid=%t, but got %v", tc.value, tc.valid, res.Failures)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Layout is the shape of a plane, given by how many characters of a boarding
+// pass pick the row, and how many pick the column.
+type Layout struct {
+	RowBits, ColBits int
+}
+
+// DefaultLayout is the plane from the puzzle, with 128 rows of 8 seats.
+var DefaultLayout = Layout{RowBits: 7, ColBits: 3}
+
+// maxBits keeps seat IDs, and the maps of them, to a sensible size.
+const maxBits = 24
+
+// Check returns an error if the layout doesn't make sense.
+func (l Layout) Check() error {
+	if l.RowBits < 0 || l.ColBits < 0 || l.RowBits+l.ColBits == 0 {
+		return fmt.Errorf("layout needs a positive number of bits, not %d row and %d column", l.RowBits, l.ColBits)
+	}
+	if l.RowBits+l.ColBits > maxBits {
+		return fmt.Errorf("layout has %d bits, more than the most of %d", l.RowBits+l.ColBits, maxBits)
+	}
+	return nil
+}
+
+// Rows returns the number of rows.
+func (l Layout) Rows() int {
+	return 1 << l.RowBits
+}
+
+// Cols returns the number of seats in a row.
+func (l Layout) Cols() int {
+	return 1 << l.ColBits
+}
+
+// Seats returns the number of seats, which is also one more than the highest
+// seat ID.
+func (l Layout) Seats() int {
+	return l.Rows() * l.Cols()
+}
+
+// ID returns the ID of the seat at a row and column.
+func (l Layout) ID(row, col int) int {
+	return row*l.Cols() + col
+}
+
+// Seat returns the row and column of a seat ID.
+func (l Layout) Seat(id int) (row, col int) {
+	return id / l.Cols(), id % l.Cols()
+}
+
+// Decode returns the seat ID for a boarding pass. The row comes first, as
+// F for the front half and B for the back, and then the column, as L for the
+// left half and R for the right.
+func (l Layout) Decode(pass string) (int, error) {
+	if len(pass) != l.RowBits+l.ColBits {
+		return 0, fmt.Errorf("boarding pass %q: expected %d characters, but got %d", pass, l.RowBits+l.ColBits, len(pass))
+	}
+	var id int
+	for i := 0; i < len(pass); i++ {
+		zero, one := byte('F'), byte('B')
+		if i >= l.RowBits {
+			zero, one = 'L', 'R'
+		}
+		id <<= 1
+		switch pass[i] {
+		case zero:
+		case one:
+			id |= 1
+		default:
+			return 0, fmt.Errorf("boarding pass %q: expected %c or %c at %d, but got %c", pass, zero, one, i+1, pass[i])
+		}
+	}
+	return id, nil
+}
+
+// Encode returns the boarding pass for a seat ID.
+func (l Layout) Encode(id int) (string, error) {
+	if id < 0 || id >= l.Seats() {
+		return "", fmt.Errorf("seat ID %d is outside the plane, which has IDs 0 to %d", id, l.Seats()-1)
+	}
+	var b strings.Builder
+	for bit := l.RowBits + l.ColBits - 1; bit >= 0; bit-- {
+		set := id&(1<<bit) != 0
+		switch {
+		case bit >= l.ColBits && set:
+			b.WriteByte('B')
+		case bit >= l.ColBits:
+			b.WriteByte('F')
+		case set:
+			b.WriteByte('R')
+		default:
+			b.WriteByte('L')
+		}
+	}
+	return b.String(), nil
+}
+
+// Flight is the seats taken on a plane.
+type Flight struct {
+	Layout Layout
+	Taken  []bool
+}
+
+// NewFlight decodes the boarding passes for a flight.
+func NewFlight(l Layout, passes []string) (*Flight, error) {
+	if err := l.Check(); err != nil {
+		return nil, err
+	}
+	f := &Flight{Layout: l, Taken: make([]bool, l.Seats())}
+	for _, pass := range passes {
+		id, err := l.Decode(pass)
+		if err != nil {
+			return nil, err
+		}
+		if f.Taken[id] {
+			return nil, fmt.Errorf("boarding pass %q: seat %d is taken twice", pass, id)
+		}
+		f.Taken[id] = true
+	}
+	return f, nil
+}
+
+// ErrEmptyFlight is returned when a flight has no seats taken.
+var ErrEmptyFlight = errors.New("no seats are taken")
+
+// MinAndMax returns the lowest and highest taken seat IDs.
+func (f *Flight) MinAndMax() (int, int, error) {
+	min, max := -1, -1
+	for id, taken := range f.Taken {
+		if !taken {
+			continue
+		}
+		if min < 0 {
+			min = id
+		}
+		max = id
+	}
+	if min < 0 {
+		return 0, 0, ErrEmptyFlight
+	}
+	return min, max, nil
+}
+
+// Gaps returns every empty seat whose neighbors, by ID, are both taken.
+func (f *Flight) Gaps() []int {
+	var gaps []int
+	for id := 1; id < len(f.Taken)-1; id++ {
+		if !f.Taken[id] && f.Taken[id-1] && f.Taken[id+1] {
+			gaps = append(gaps, id)
+		}
+	}
+	return gaps
+}
+
+// WriteMap draws the plane a row to a line, front first, with # for a taken
+// seat, O for a gap, and . for any other empty seat. Rows before the first
+// taken seat and after the last are left out.
+func (f *Flight) WriteMap(w io.Writer) error {
+	min, max, err := f.MinAndMax()
+	if err != nil {
+		return err
+	}
+	var (
+		l       = f.Layout
+		gaps    = make(map[int]bool)
+		first   = min / l.Cols()
+		last    = max / l.Cols()
+		numbers = len(fmt.Sprint(last))
+	)
+	for _, id := range f.Gaps() {
+		gaps[id] = true
+	}
+
+	bw := bufio.NewWriter(w)
+	for row := first; row <= last; row++ {
+		fmt.Fprintf(bw, "%*d ", numbers, row)
+		for col := 0; col < l.Cols(); col++ {
+			id := l.ID(row, col)
+			switch {
+			case f.Taken[id]:
+				bw.WriteByte('#')
+			case gaps[id]:
+				bw.WriteByte('O')
+			default:
+				bw.WriteByte('.')
+			}
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
@@ -3,130 +3,126 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
 	"io"
-	"os"
+	"strconv"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/answer"
+	"github.com/ianfoo/advent-of-code-2020/internal/input"
+	"github.com/ianfoo/advent-of-code-2020/internal/runner"
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
 )
 
+// plane solves the puzzle, and works with boarding passes, for a plane with
+// the given layout.
+type plane struct {
+	layout Layout
+}
+
 func main() {
-	if err := run(os.Stdin); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
-	}
+	p := &plane{layout: DefaultLayout}
+	flag.IntVar(&p.layout.RowBits, "row-bits", DefaultLayout.RowBits, "Number of characters in a boarding pass that pick the row.")
+	flag.IntVar(&p.layout.ColBits, "col-bits", DefaultLayout.ColBits, "Number of characters in a boarding pass that pick the column.")
+	runner.Solution{
+		Year:     2020,
+		Day:      5,
+		Solve:    p.run,
+		Generate: p.generate,
+		Commands: map[string]runner.Command{
+			"encode": {
+				Args:    "id...",
+				Summary: "Print the boarding pass for each seat ID.",
+				Run:     p.encode,
+			},
+			"decode": {
+				Args:    "pass...",
+				Summary: "Print the row, column and seat ID for each boarding pass.",
+				Run:     p.decode,
+			},
+			"map": {
+				Summary: "Draw the seats taken by the boarding passes read from standard input.",
+				Run:     p.drawMap,
+			},
+		},
+	}.Main()
 }
 
-func run(r io.Reader) error {
-	input, err := readInput(r)
+func (p *plane) run(r io.Reader, s *runner.Session) error {
+	flight, err := readFlight(r, p.layout)
 	if err != nil {
-		return fmt.Errorf("reading input: %w", err)
+		return err
 	}
 
-	var (
-		minSeatID int
-		maxSeatID int
-	)
-	{
-		var err error
-		minSeatID, maxSeatID, err = findMinAndMaxSeatID(input)
+	s.Part(1, func(log *trace.Logger) (answer.Answer, error) {
+		_, maxSeatID, err := flight.MinAndMax()
 		if err != nil {
-			return fmt.Errorf("part 1: %w", err)
+			return answer.Answer{}, err
 		}
-		fmt.Printf("Maximum Seat ID: %d\n", maxSeatID)
-	}
+		log.Infof("maximum seat ID: %d", maxSeatID)
+		return answer.Int(maxSeatID), nil
+	})
 
-	{
-		missingSeatID, err := findMissingSeatID(input, minSeatID, maxSeatID)
-		if err != nil {
-			return fmt.Errorf("part 2: %w", err)
+	s.Part(2, func(log *trace.Logger) (answer.Answer, error) {
+		gaps := flight.Gaps()
+		if len(gaps) != 1 {
+			return answer.Answer{}, fmt.Errorf("expected one empty seat between two taken ones, but found %d: %v", len(gaps), gaps)
 		}
-		fmt.Printf("Missing Seat ID: %d\n", missingSeatID)
-	}
+		log.Infof("missing seat ID: %d", gaps[0])
+		return answer.Int(gaps[0]), nil
+	})
 
 	return nil
 }
 
-func readInput(r io.Reader) ([]string, error) {
-	var (
-		s     = bufio.NewScanner(r)
-		input []string
-	)
-	for s.Scan() {
-		line := s.Text()
-		input = append(input, line)
-	}
-	if err := s.Err(); err != nil {
-		return nil, err
+func readFlight(r io.Reader, l Layout) (*Flight, error) {
+	lines, err := input.Lines(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading lines: %w", err)
 	}
-	return input, nil
+	return NewFlight(l, lines)
 }
 
-// decodeSeat walks through the boarding pass string and decodes the
-// row and seat, and calculates the seat ID.
-func decodeSeat(s string) int {
-	const (
-		rowIDLength = 7
-		colIDLength = 3
-	)
-	var (
-		row int
-		col int
-	)
-	for i := 0; i < rowIDLength; i++ {
-		if s[i] == 'B' {
-			pow := (rowIDLength - 1) - i
-			row += 1 << pow
-		}
+func (p *plane) encode(args []string, stdin io.Reader, stdout io.Writer, log *trace.Logger) error {
+	if err := p.layout.Check(); err != nil {
+		return err
 	}
-	for i := 0; i < colIDLength; i++ {
-		offset := rowIDLength + i
-		if s[offset] == 'R' {
-			pow := (colIDLength - 1) - i
-			col += 1 << pow
+	for _, arg := range args {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return fmt.Errorf("bad seat ID: %w", err)
 		}
+		pass, err := p.layout.Encode(id)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, pass)
 	}
-	seatID := row*8 + col
-	return seatID
-
+	return nil
 }
 
-func findMinAndMaxSeatID(passes []string) (int, int, error) {
-	const maxPossibleSeatID = 127*8 + 8
-	var (
-		min = maxPossibleSeatID
-		max = 0
-	)
-	for _, pass := range passes {
-		seatID := decodeSeat(pass)
-		if seatID > max {
-			max = seatID
-			continue
-		}
-		if seatID < min {
-			min = seatID
+func (p *plane) decode(args []string, stdin io.Reader, stdout io.Writer, log *trace.Logger) error {
+	if err := p.layout.Check(); err != nil {
+		return err
+	}
+	for _, pass := range args {
+		id, err := p.layout.Decode(pass)
+		if err != nil {
+			return err
 		}
+		row, col := p.layout.Seat(id)
+		fmt.Fprintf(stdout, "%s: row %d, column %d, seat ID %d\n", pass, row, col, id)
 	}
-	return min, max, nil
+	return nil
 }
 
-// findMissingSeatID finds which boarding pass is missing by using the knowledge
-// that seat IDs are consecutive from minimum to maximum, and comparing the sum
-// of those values with the sum of the values represented in the acutal list of
-// boarding passes, which is missing one value. The difference between the sums
-// will indicate which seat is missing.
-func findMissingSeatID(passes []string, minSeatID, maxSeatID int) (int, error) {
-	var (
-		seatIDsPerfectSum int
-		seatIDsActualSum  int
-	)
-	for i := minSeatID; i <= maxSeatID; i++ {
-		seatIDsPerfectSum += i
+func (p *plane) drawMap(args []string, stdin io.Reader, stdout io.Writer, log *trace.Logger) error {
+	if len(args) > 0 {
+		return fmt.Errorf("unexpected arguments %q", args)
 	}
-	for _, pass := range passes {
-		seatID := decodeSeat(pass)
-		seatIDsActualSum += seatID
+	flight, err := readFlight(stdin, p.layout)
+	if err != nil {
+		return err
 	}
-
-	missingSeatID := seatIDsPerfectSum - seatIDsActualSum
-	return missingSeatID, nil
+	return flight.WriteMap(stdout)
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -17,9 +18,84 @@ func TestDecodeSeat(t *testing.T) {
 	}
 	for _, tc := range tt {
 		t.Run(tc.boardingPass, func(t *testing.T) {
-			if want, got := tc.expectedSeatID, decodeSeat(tc.boardingPass); want != got {
+			got, err := DefaultLayout.Decode(tc.boardingPass)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := tc.expectedSeatID; want != got {
 				t.Fatalf("expected seat ID %d, but got %d", want, got)
 			}
+			pass, err := DefaultLayout.Encode(got)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if pass != tc.boardingPass {
+				t.Fatalf("expected %d to encode as %q, but got %q", got, tc.boardingPass, pass)
+			}
 		})
 	}
 }
+
+func TestLayout(t *testing.T) {
+	l := Layout{RowBits: 2, ColBits: 4}
+	for id := 0; id < l.Seats(); id++ {
+		pass, err := l.Encode(id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got, err := l.Decode(pass)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != id {
+			t.Fatalf("expected %q to decode as %d, but got %d", pass, id, got)
+		}
+	}
+	if pass, _ := l.Encode(37); pass != "BFLRLR" {
+		t.Errorf("expected BFLRLR, but got %q", pass)
+	}
+
+	for _, pass := range []string{"BFRLR", "BFRLRRR", "BFRBRR", "LFRLRR"} {
+		if _, err := l.Decode(pass); err == nil {
+			t.Errorf("expected an error decoding %q", pass)
+		}
+	}
+	if _, err := l.Encode(64); err == nil {
+		t.Error("expected an error encoding a seat outside the plane")
+	}
+}
+
+func TestFlight(t *testing.T) {
+	l := Layout{RowBits: 2, ColBits: 2}
+	var passes []string
+	for _, id := range []int{2, 3, 5, 7, 8, 10, 11} {
+		pass, _ := l.Encode(id)
+		passes = append(passes, pass)
+	}
+	f, err := NewFlight(l, passes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The lowest seat comes after the highest, to check that seeing a new
+	// maximum doesn't skip updating the minimum.
+	min, max, err := f.MinAndMax()
+	if err != nil || min != 2 || max != 11 {
+		t.Errorf("expected 2 and 11, but got %d and %d (%v)", min, max, err)
+	}
+	if gaps := f.Gaps(); len(gaps) != 3 || gaps[0] != 4 || gaps[1] != 6 || gaps[2] != 9 {
+		t.Errorf("expected gaps [4 6 9], but got %v", gaps)
+	}
+
+	var out strings.Builder
+	if err := f.WriteMap(&out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "0 ..##\n1 O#O#\n2 #O##\n"; out.String() != want {
+		t.Errorf("expected map %q, but got %q", want, out.String())
+	}
+
+	if _, err := NewFlight(l, append(passes, passes[0])); err == nil {
+		t.Error("expected an error for a seat taken twice")
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"math/rand"
+)
+
+// generate makes boarding passes for a run of size seats with one empty seat
+// somewhere in the middle, in random order.
+func (p *plane) generate(rng *rand.Rand, size int) []string {
+	if size < 3 {
+		size = 3
+	}
+	// Planes too small to hold a gap fall back to the puzzle's.
+	layout := DefaultLayout
+	if p.layout.Check() == nil && p.layout.Seats() >= 4 {
+		layout = p.layout
+	}
+	if size > layout.Seats()-1 {
+		size = layout.Seats() - 1
+	}
+	var (
+		first = rng.Intn(layout.Seats() - size)
+		empty = first + 1 + rng.Intn(size-2)
+		lines []string
+	)
+	for id := first; id < first+size; id++ {
+		if id != empty {
+			pass, _ := layout.Encode(id)
+			lines = append(lines, pass)
+		}
+	}
+	rng.Shuffle(len(lines), func(i, j int) { lines[i], lines[j] = lines[j], lines[i] })
+	return lines
+}
@@ -4,114 +4,185 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"io"
-	"os"
+	"sort"
+	"strings"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/answer"
+	"github.com/ianfoo/advent-of-code-2020/internal/input"
+	"github.com/ianfoo/advent-of-code-2020/internal/letters"
+	"github.com/ianfoo/advent-of-code-2020/internal/runner"
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
 )
 
 func main() {
-	if err := run(os.Stdin); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
-	}
+	runner.Solution{
+		Year:     2020,
+		Day:      6,
+		Solve:    run,
+		Generate: generate,
+		Commands: map[string]runner.Command{
+			"breakdown": {
+				Args:    "[-mode union|intersection|at-least|exactly-one|symmetric-difference] [-k n]",
+				Summary: "Show the questions each group read from standard input answered, combined as -mode says.",
+				Run:     breakdown,
+			},
+		},
+	}.Main()
 }
 
-func run(r io.Reader) error {
-	input, err := readInput(r)
+func run(r io.Reader, s *runner.Session) error {
+	paragraphs, err := input.Paragraphs(r)
 	if err != nil {
-		return fmt.Errorf("reading input: %w", err)
+		return fmt.Errorf("reading groups: %w", err)
 	}
-
-	{
-		result, err := part1(input)
-		if err != nil {
-			return fmt.Errorf("part 1: %w", err)
-		}
-		fmt.Printf("Part 1: %d\n", result)
+	groups, err := parseGroups(paragraphs)
+	if err != nil {
+		return err
 	}
 
-	{
-		result, err := part2(input)
-		if err != nil {
-			return fmt.Errorf("part 2: %w", err)
+	s.Part(1, func(log *trace.Logger) (answer.Answer, error) {
+		return answer.Int(sumGroups(groups, modes["union"], 0)), nil
+	})
+	s.Impl(1, "maps", func(log *trace.Logger) (answer.Answer, error) {
+		result, err := part1Maps(paragraphs)
+		return answer.Int(result), err
+	})
+
+	s.Part(2, func(log *trace.Logger) (answer.Answer, error) {
+		return answer.Int(sumGroups(groups, modes["intersection"], 0)), nil
+	})
+	s.Impl(2, "maps", func(log *trace.Logger) (answer.Answer, error) {
+		result, err := part2Maps(paragraphs)
+		return answer.Int(result), err
+	})
+
+	return nil
+}
+
+// parseGroups reads each person's answers as a set of letters.
+func parseGroups(paragraphs [][]string) ([][]letters.Set, error) {
+	groups := make([][]letters.Set, len(paragraphs))
+	for i, group := range paragraphs {
+		groups[i] = make([]letters.Set, len(group))
+		for j, line := range group {
+			s, err := letters.Of(line)
+			if err != nil {
+				return nil, fmt.Errorf("group %d, person %d: %w", i+1, j+1, err)
+			}
+			groups[i][j] = s
 		}
-		fmt.Printf("Part 2: %d\n", result)
 	}
+	return groups, nil
+}
 
-	return nil
+// mode combines the answers of the people in a group.
+type mode func(k int, people ...letters.Set) letters.Set
+
+// modes are the ways of combining answers, by name. Only at-least uses k.
+var modes = map[string]mode{
+	"union":                func(_ int, people ...letters.Set) letters.Set { return letters.Union(people...) },
+	"intersection":         func(_ int, people ...letters.Set) letters.Set { return letters.Intersection(people...) },
+	"at-least":             letters.AtLeast,
+	"exactly-one":          func(_ int, people ...letters.Set) letters.Set { return letters.Exactly(1, people...) },
+	"symmetric-difference": func(_ int, people ...letters.Set) letters.Set { return letters.SymmetricDifference(people...) },
 }
 
-func readInput(r io.Reader) ([]string, error) {
-	var (
-		s     = bufio.NewScanner(r)
-		input []string
-	)
-	for s.Scan() {
-		line := s.Text()
-		input = append(input, line)
+func modeNames() []string {
+	names := make([]string, 0, len(modes))
+	for name := range modes {
+		names = append(names, name)
 	}
-	if err := s.Err(); err != nil {
-		return nil, err
+	sort.Strings(names)
+	return names
+}
+
+// sumGroups adds up the number of questions in each group's combined answers.
+func sumGroups(groups [][]letters.Set, m mode, k int) int {
+	var sum int
+	for _, group := range groups {
+		sum += m(k, group...).Len()
 	}
-	return input, nil
+	return sum
 }
 
-func part1(input []string) (int, error) {
+// breakdown prints each group's combined answers, and their total. The -mode
+// and -k flags belong to it rather than to the solution, whose parts are the
+// union and the intersection that the puzzle asks for, and are checked
+// against the recorded answers.
+func breakdown(args []string, stdin io.Reader, stdout io.Writer, log *trace.Logger) error {
+	fs := flag.NewFlagSet("breakdown", flag.ContinueOnError)
 	var (
-		sum    int
-		yesses = make(map[rune]struct{})
+		name = fs.String("mode", "union", "How to combine a group's answers: "+strings.Join(modeNames(), ", ")+".")
+		k    = fs.Int("k", 1, "Number of people for the at-least mode.")
 	)
-	for _, line := range input {
-		if line == "" {
-			sum += len(yesses)
-			yesses = make(map[rune]struct{})
-			continue
-		}
-		for _, question := range line {
-			yesses[question] = struct{}{}
-		}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	m, ok := modes[*name]
+	if !ok {
+		return fmt.Errorf("unknown mode %q; choose from %s", *name, strings.Join(modeNames(), ", "))
 	}
 
-	// Don't forget the last group.
-	sum += len(yesses)
+	paragraphs, err := input.Paragraphs(stdin)
+	if err != nil {
+		return fmt.Errorf("reading groups: %w", err)
+	}
+	groups, err := parseGroups(paragraphs)
+	if err != nil {
+		return err
+	}
 
-	return sum, nil
+	bw := bufio.NewWriter(stdout)
+	fmt.Fprintf(bw, "GROUP PEOPLE COUNT QUESTIONS\n")
+	var sum int
+	for i, group := range groups {
+		s := m(*k, group...)
+		sum += s.Len()
+		questions := s.String()
+		if questions == "" {
+			questions = "-"
+		}
+		fmt.Fprintf(bw, "%5d %6d %5d %s\n", i+1, len(group), s.Len(), questions)
+	}
+	fmt.Fprintf(bw, "total: %d\n", sum)
+	return bw.Flush()
 }
 
-func part2(input []string) (int, error) {
-	var (
-		sum       int
-		yesses    = make(map[rune]int)
-		groupSize int
-	)
-	countUnanimous := func(yesses map[rune]int, groupSize int) int {
-		unanimousYes := make([]rune, 0, len(yesses))
-		for question, count := range yesses {
-			if count == groupSize {
-				unanimousYes = append(unanimousYes, question)
+// part1Maps counts the questions anyone in each group answered, using a map
+// for each group.
+func part1Maps(groups [][]string) (int, error) {
+	var sum int
+	for _, group := range groups {
+		yesses := make(map[rune]struct{})
+		for _, line := range group {
+			for _, question := range line {
+				yesses[question] = struct{}{}
 			}
 		}
-		return len(unanimousYes)
+		sum += len(yesses)
 	}
-	for _, line := range input {
-		if line == "" {
-			numUnanimousYes := countUnanimous(yesses, groupSize)
-			sum += numUnanimousYes
-
-			// Reset the tracking state.
-			yesses = make(map[rune]int)
-			groupSize = 0
-			continue
-		}
+	return sum, nil
+}
 
-		groupSize++
-		for _, question := range line {
-			yesses[question] += 1
+// part2Maps counts the questions everyone in each group answered, using a
+// map for each group.
+func part2Maps(groups [][]string) (int, error) {
+	var sum int
+	for _, group := range groups {
+		yesses := make(map[rune]int)
+		for _, line := range group {
+			for _, question := range line {
+				yesses[question]++
+			}
+		}
+		for _, count := range yesses {
+			if count == len(group) {
+				sum++
+			}
 		}
 	}
-
-	// Don't forget the last group.
-	sum += countUnanimous(yesses, groupSize)
-
 	return sum, nil
 }
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/input"
+)
+
+func TestParseGroups(t *testing.T) {
+	f, err := os.Open("sample.txt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+	paragraphs, err := input.Paragraphs(f)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	groups, err := parseGroups(paragraphs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tt := []struct {
+		mode string
+		k    int
+		want int
+	}{
+		{mode: "union", want: 11},
+		{mode: "intersection", want: 6},
+		{mode: "at-least", k: 2, want: 2},
+		{mode: "exactly-one", want: 9},
+		{mode: "symmetric-difference", want: 9},
+	}
+	for _, tc := range tt {
+		if got := sumGroups(groups, modes[tc.mode], tc.k); got != tc.want {
+			t.Errorf("%s: expected %d, but got %d", tc.mode, tc.want, got)
+		}
+	}
+
+	if _, err := parseGroups([][]string{{"ab"}, {"a", "b1"}}); err == nil || !strings.HasPrefix(err.Error(), "group 2, person 2:") {
+		t.Errorf("expected an error for group 2, person 2, but got %v", err)
+	}
+}
+
+func TestBreakdown(t *testing.T) {
+	f, err := os.Open("sample.txt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+	var out strings.Builder
+	if err := breakdown([]string{"-mode", "at-least", "-k", "2"}, f, &out, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `GROUP PEOPLE COUNT QUESTIONS
+    1      1     0 -
+    2      3     0 -
+    3      2     1 a
+    4      4     1 a
+    5      1     0 -
+total: 2
+`
+	if out.String() != want {
+		t.Errorf("expected:\n%s\nbut got:\n%s", want, out.String())
+	}
+	if err := breakdown([]string{"-mode", "odd"}, strings.NewReader("a\n"), &out, nil); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"math/rand"
+)
+
+// generate makes size groups of up to five people, each answering yes to a
+// few questions.
+func generate(rng *rand.Rand, size int) []string {
+	const questions = "abcdefghijklmnopqrstuvwxyz"
+	var lines []string
+	for i := 0; i < size; i++ {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		for p := 1 + rng.Intn(5); p > 0; p-- {
+			// Keep to the first few questions, so that groups have some
+			// answers in common.
+			var (
+				perm    = rng.Perm(8 + rng.Intn(len(questions)-7))
+				answers = make([]byte, 1+rng.Intn(len(perm)))
+			)
+			for j := range answers {
+				answers[j] = questions[perm[j]]
+			}
+			lines = append(lines, string(answers))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,421 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/input"
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
+)
+
+// Match rule lines and the bags in them:
+// [color] bags contain no other bags.
+// [color] bags contain [contents].
+// [count] [color] bag(s)
+var (
+	rulePat        = regexp.MustCompile(`^(?P<color>.+?) bags contain (?:no other bags|(?P<contents>.+?))\.?$`)
+	containablePat = regexp.MustCompile(`^(?P<count>\d+) (?P<color>.+) bags?$`)
+
+	ruleDecoder        = input.NewDecoder(rulePat)
+	containableDecoder = input.NewDecoder(containablePat)
+)
+
+// rule is a line of the rules, with the bags inside, if any, as written.
+type rule struct {
+	Color    string   `aoc:"color"`
+	Contents []string `aoc:"contents,sep=, "`
+}
+
+// containable is a number of bags of a color inside another bag.
+type containable struct {
+	Count int    `aoc:"count"`
+	Color string `aoc:"color"`
+}
+
+// ErrUnknownColor is returned for a color that isn't in any rule.
+var ErrUnknownColor = errors.New("unknown color")
+
+// CycleError is returned for rules in which a bag ends up inside itself,
+// which would make for infinitely many bags.
+type CycleError struct {
+	// Colors are the bags in the cycle, starting and ending with the same
+	// one.
+	Colors []string
+}
+
+func (e *CycleError) Error() string {
+	return "bag rules have a cycle: " + strings.Join(e.Colors, " -> ")
+}
+
+// BagGraph is the rules for which bags go inside which. Answers to questions
+// about it are remembered, so asking again is cheap.
+type BagGraph struct {
+	// contents maps each color to the colors and numbers of bags inside it.
+	contents map[string]map[string]int
+
+	// containers maps each color to the colors that hold it directly,
+	// sorted.
+	containers map[string][]string
+
+	// ruled is the colors with rules of their own.
+	ruled map[string]bool
+
+	ancestors map[string][]string
+	inside    map[string]int
+	depth     map[string]int
+}
+
+// ParseBagGraph reads rules like "light red bags contain 1 bright white bag,
+// 2 muted yellow bags." Colors that only turn up inside other bags are taken
+// to hold nothing.
+func ParseBagGraph(lines []string, log *trace.Logger) (*BagGraph, error) {
+	g := &BagGraph{
+		contents:   make(map[string]map[string]int),
+		containers: make(map[string][]string),
+		ruled:      make(map[string]bool),
+		ancestors:  make(map[string][]string),
+		inside:     make(map[string]int),
+		depth:      make(map[string]int),
+	}
+	for i, line := range lines {
+		var r rule
+		sequence := i + 1
+		if err := ruleDecoder.Decode(line, sequence, &r); err != nil {
+			return nil, fmt.Errorf("unexpected container rule format: %w", err)
+		}
+
+		containerColor := r.Color
+		if g.ruled[containerColor] {
+			return nil, fmt.Errorf("[%d] second rule for %q", sequence, containerColor)
+		}
+		g.ruled[containerColor] = true
+		if g.contents[containerColor] == nil {
+			g.contents[containerColor] = make(map[string]int)
+		}
+
+		if len(r.Contents) == 0 {
+			log.Tracef("[%4d] rule: %s contains nothing", sequence, containerColor)
+			continue
+		}
+
+		log.Tracef("[%4d] rule: %s contains %s", sequence, containerColor, strings.Join(r.Contents, ", "))
+		for _, text := range r.Contents {
+			var c containable
+			if err := containableDecoder.Decode(text, sequence, &c); err != nil {
+				return nil, fmt.Errorf("unexpected capacity format: %w", err)
+			}
+			g.contents[containerColor][c.Color] = c.Count
+			g.containers[c.Color] = append(g.containers[c.Color], containerColor)
+			if g.contents[c.Color] == nil {
+				g.contents[c.Color] = make(map[string]int)
+			}
+		}
+	}
+
+	for _, holders := range g.containers {
+		sort.Strings(holders)
+	}
+	for _, color := range g.Unruled() {
+		log.Warnf("%q has no rule of its own, so it's taken to hold nothing", color)
+	}
+	if err := g.checkCycles(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// checkCycles returns a CycleError if any bag ends up inside itself.
+func (g *BagGraph) checkCycles() error {
+	const (
+		unseen = iota
+		visiting
+		done
+	)
+	state := make(map[string]int)
+	var path []string
+
+	var visit func(color string) error
+	visit = func(color string) error {
+		switch state[color] {
+		case done:
+			return nil
+		case visiting:
+			for i, c := range path {
+				if c == color {
+					cycle := append(append([]string{}, path[i:]...), color)
+					return &CycleError{Colors: cycle}
+				}
+			}
+		}
+		state[color] = visiting
+		path = append(path, color)
+		for _, inner := range sorted(g.contents[color]) {
+			if err := visit(inner); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[color] = done
+		return nil
+	}
+	for _, color := range g.Colors() {
+		if err := visit(color); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sorted returns the colors in a bag's contents in order.
+func sorted(contents map[string]int) []string {
+	colors := make([]string, 0, len(contents))
+	for color := range contents {
+		colors = append(colors, color)
+	}
+	sort.Strings(colors)
+	return colors
+}
+
+func (g *BagGraph) check(color string) error {
+	if _, ok := g.contents[color]; !ok {
+		return fmt.Errorf("%w %q", ErrUnknownColor, color)
+	}
+	return nil
+}
+
+// Colors returns every color in the rules, in order.
+func (g *BagGraph) Colors() []string {
+	colors := make([]string, 0, len(g.contents))
+	for color := range g.contents {
+		colors = append(colors, color)
+	}
+	sort.Strings(colors)
+	return colors
+}
+
+// Unruled returns the colors that only turn up inside other bags, without a
+// rule of their own.
+func (g *BagGraph) Unruled() []string {
+	var colors []string
+	for _, color := range g.Colors() {
+		if !g.ruled[color] {
+			colors = append(colors, color)
+		}
+	}
+	return colors
+}
+
+// Contents returns how many of each color of bag go directly inside a bag of
+// the given color.
+func (g *BagGraph) Contents(color string) (map[string]int, error) {
+	if err := g.check(color); err != nil {
+		return nil, err
+	}
+	contents := make(map[string]int, len(g.contents[color]))
+	for inner, n := range g.contents[color] {
+		contents[inner] = n
+	}
+	return contents, nil
+}
+
+// Containers returns the colors of bag that hold the given color directly.
+func (g *BagGraph) Containers(color string) ([]string, error) {
+	if err := g.check(color); err != nil {
+		return nil, err
+	}
+	return append([]string{}, g.containers[color]...), nil
+}
+
+// Ancestors returns, in order, every color of bag that can end up holding the
+// given color, however deep down.
+func (g *BagGraph) Ancestors(color string) ([]string, error) {
+	if err := g.check(color); err != nil {
+		return nil, err
+	}
+	return append([]string{}, g.ancestorsOf(color)...), nil
+}
+
+func (g *BagGraph) ancestorsOf(color string) []string {
+	if ancestors, ok := g.ancestors[color]; ok {
+		return ancestors
+	}
+	seen := make(map[string]bool)
+	for _, holder := range g.containers[color] {
+		seen[holder] = true
+		for _, a := range g.ancestorsOf(holder) {
+			seen[a] = true
+		}
+	}
+	ancestors := make([]string, 0, len(seen))
+	for a := range seen {
+		ancestors = append(ancestors, a)
+	}
+	sort.Strings(ancestors)
+	g.ancestors[color] = ancestors
+	return ancestors
+}
+
+// BagsInside returns the total number of bags inside a bag of the given
+// color, not counting the bag itself.
+func (g *BagGraph) BagsInside(color string) (int, error) {
+	if err := g.check(color); err != nil {
+		return 0, err
+	}
+	return g.bagsInside(color), nil
+}
+
+func (g *BagGraph) bagsInside(color string) int {
+	if n, ok := g.inside[color]; ok {
+		return n
+	}
+	var n int
+	for inner, count := range g.contents[color] {
+		n += count * (1 + g.bagsInside(inner))
+	}
+	g.inside[color] = n
+	return n
+}
+
+// Depth returns how many levels of bags a bag of the given color holds: 0 if
+// it holds nothing, 1 if it only holds empty bags, and so on.
+func (g *BagGraph) Depth(color string) (int, error) {
+	if err := g.check(color); err != nil {
+		return 0, err
+	}
+	return g.depthOf(color), nil
+}
+
+func (g *BagGraph) depthOf(color string) int {
+	if d, ok := g.depth[color]; ok {
+		return d
+	}
+	var d int
+	for inner := range g.contents[color] {
+		if n := 1 + g.depthOf(inner); n > d {
+			d = n
+		}
+	}
+	g.depth[color] = d
+	return d
+}
+
+// Deepest returns the color of bag with the most levels of bags inside it,
+// and how many. Ties go to the first color in order.
+func (g *BagGraph) Deepest() (string, int) {
+	var (
+		deepest string
+		max     = -1
+	)
+	for _, color := range g.Colors() {
+		if d := g.depthOf(color); d > max {
+			deepest, max = color, d
+		}
+	}
+	return deepest, max
+}
+
+// Content is a number of bags of one color, and what's inside each of them.
+type Content struct {
+	Color    string    `json:"color"`
+	Count    int       `json:"count"`
+	Contents []Content `json:"contents,omitempty"`
+}
+
+// Tree returns what's inside a bag of the given color, and what's inside
+// those, down to the given number of levels.
+func (g *BagGraph) Tree(color string, levels int) ([]Content, error) {
+	if err := g.check(color); err != nil {
+		return nil, err
+	}
+	return g.tree(color, levels), nil
+}
+
+func (g *BagGraph) tree(color string, levels int) []Content {
+	if levels <= 0 {
+		return nil
+	}
+	var contents []Content
+	for _, inner := range sorted(g.contents[color]) {
+		contents = append(contents, Content{
+			Color:    inner,
+			Count:    g.contents[color][inner],
+			Contents: g.tree(inner, levels-1),
+		})
+	}
+	return contents
+}
+
+// Explain returns the shortest chain of bags from outer down to target, each
+// directly inside the one before, or nil if outer can't hold target.
+func (g *BagGraph) Explain(outer, target string) ([]string, error) {
+	for _, color := range []string{outer, target} {
+		if err := g.check(color); err != nil {
+			return nil, err
+		}
+	}
+	from := map[string]string{outer: ""}
+	queue := []string{outer}
+	for len(queue) > 0 {
+		color := queue[0]
+		queue = queue[1:]
+		if color == target && color != outer {
+			var path []string
+			for c := color; c != ""; c = from[c] {
+				path = append([]string{c}, path...)
+			}
+			return path, nil
+		}
+		for _, inner := range sorted(g.contents[color]) {
+			if _, ok := from[inner]; !ok {
+				from[inner] = color
+				queue = append(queue, inner)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// WriteDOT writes, in Graphviz's DOT language, the part of the graph around
+// a color: every bag that can hold it, and every bag it holds. Edges go from
+// each bag to the ones inside it, labeled with how many.
+func (g *BagGraph) WriteDOT(w io.Writer, color string) error {
+	if err := g.check(color); err != nil {
+		return err
+	}
+	var (
+		include = map[string]bool{color: true}
+		colors  = append([]string{color}, g.ancestorsOf(color)...)
+	)
+	for _, a := range g.ancestorsOf(color) {
+		include[a] = true
+	}
+	for queue := []string{color}; len(queue) > 0; queue = queue[1:] {
+		for inner := range g.contents[queue[0]] {
+			if !include[inner] {
+				include[inner] = true
+				colors = append(colors, inner)
+				queue = append(queue, inner)
+			}
+		}
+	}
+	sort.Strings(colors)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "digraph bags {\n")
+	fmt.Fprintf(bw, "\tnode [shape=box];\n")
+	fmt.Fprintf(bw, "\t%q [style=filled, fillcolor=gold];\n", color)
+	for _, c := range colors {
+		for _, inner := range sorted(g.contents[c]) {
+			if include[inner] {
+				fmt.Fprintf(bw, "\t%q -> %q [label=%d];\n", c, inner, g.contents[c][inner])
+			}
+		}
+	}
+	fmt.Fprintf(bw, "}\n")
+	return bw.Flush()
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/input"
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
+)
+
+func readSample(t *testing.T, name string) *BagGraph {
+	t.Helper()
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+	lines, err := input.Lines(f)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g, err := ParseBagGraph(lines, trace.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return g
+}
+
+func TestBagGraph(t *testing.T) {
+	tt := []struct {
+		file      string
+		color     string
+		ancestors []string
+		inside    int
+	}{
+		{
+			file:      "sample-input.txt",
+			color:     DefaultBagColor,
+			ancestors: []string{"bright white", "dark orange", "light red", "muted yellow"},
+			inside:    32,
+		},
+		{
+			file:      "sample-input.txt",
+			color:     "faded blue",
+			ancestors: []string{"bright white", "dark olive", "dark orange", "light red", "muted yellow", "shiny gold", "vibrant plum"},
+		},
+		{file: "sample-input-part-2.txt", color: DefaultBagColor, inside: 126},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.file+"/"+tc.color, func(t *testing.T) {
+			t.Parallel()
+			g := readSample(t, tc.file)
+			ancestors, err := g.Ancestors(tc.color)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(ancestors) == 0 {
+				ancestors = nil
+			}
+			if !reflect.DeepEqual(ancestors, tc.ancestors) {
+				t.Errorf("expected ancestors %q, but got %q", tc.ancestors, ancestors)
+			}
+			inside, err := g.BagsInside(tc.color)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if inside != tc.inside {
+				t.Errorf("expected %d bags inside, but got %d", tc.inside, inside)
+			}
+		})
+	}
+}
+
+func TestExplain(t *testing.T) {
+	g := readSample(t, "sample-input.txt")
+	tt := []struct {
+		outer string
+		want  []string
+	}{
+		{outer: "dark orange", want: []string{"dark orange", "bright white", "shiny gold"}},
+		{outer: "muted yellow", want: []string{"muted yellow", "shiny gold"}},
+		{outer: "shiny gold"},
+		{outer: "faded blue"},
+	}
+	for _, tc := range tt {
+		path, err := g.Explain(tc.outer, DefaultBagColor)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(path, tc.want) {
+			t.Errorf("%s: expected %q, but got %q", tc.outer, tc.want, path)
+		}
+	}
+
+	if _, err := g.Explain("plaid", DefaultBagColor); !errors.Is(err, ErrUnknownColor) {
+		t.Errorf("expected %v, but got %v", ErrUnknownColor, err)
+	}
+}
+
+func TestParseBagGraphCycle(t *testing.T) {
+	rules := []string{
+		"a bags contain 1 b bag.",
+		"b bags contain 2 c bags.",
+		"c bags contain 1 a bag, 1 d bag.",
+	}
+	_, err := ParseBagGraph(rules, trace.Discard)
+	var ce *CycleError
+	if !errors.As(err, &ce) {
+		t.Fatalf("expected a cycle error, but got %v", err)
+	}
+	if want := "a -> b -> c -> a"; strings.Join(ce.Colors, " -> ") != want {
+		t.Errorf("expected %q, but got %q", want, ce.Colors)
+	}
+}
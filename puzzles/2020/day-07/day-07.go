@@ -3,192 +3,125 @@
 package main
 
 import (
-	"bufio"
 	"flag"
 	"fmt"
 	"io"
-	"log"
 	"os"
-	"regexp"
-	"strconv"
 	"strings"
-)
-
-const myBagColor = "shiny gold"
-
-var verbose bool
 
-func main() {
-	flag.BoolVar(&verbose, "verbose", false, "Print more detail.")
-	flag.Parse()
+	"github.com/ianfoo/advent-of-code-2020/internal/answer"
+	"github.com/ianfoo/advent-of-code-2020/internal/input"
+	"github.com/ianfoo/advent-of-code-2020/internal/runner"
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
+)
 
-	log.SetFlags(log.Ltime | log.Lmicroseconds)
+// DefaultBagColor is the color of my bag.
+const DefaultBagColor = "shiny gold"
 
-	if err := run(os.Stdin); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
-	}
+// solver answers questions about the bag of one color.
+type solver struct {
+	color string
 }
 
-func trace(format string, params ...interface{}) {
-	if verbose {
-		log.Printf(format, params...)
-	}
+func main() {
+	sol := &solver{}
+	flag.StringVar(&sol.color, "color", DefaultBagColor, "Color of the bag to ask about.")
+	runner.Solution{
+		Year:     2020,
+		Day:      7,
+		Solve:    sol.run,
+		Generate: generate,
+		Commands: map[string]runner.Command{
+			"dot": {
+				Args:    "[-rules path]",
+				Summary: "Print the rules that involve the -color bag in Graphviz's DOT language.",
+				Run:     sol.dot,
+			},
+			"query": {
+				Args:    "[-rules path] [-format text|json] [query]",
+				Summary: "Answer a query about the rules, or queries read from standard input; the help query lists them.",
+				Run:     query,
+			},
+		},
+	}.Main()
 }
 
-func run(r io.Reader) error {
-	input, err := readInput(r)
+func (sol *solver) run(r io.Reader, s *runner.Session) error {
+	color := sol.color
+	g, err := readBagGraph(r, s.Log)
 	if err != nil {
-		return fmt.Errorf("reading input: %w", err)
-	}
-
-	if err := parseRules(input); err != nil {
 		return err
 	}
 
-	part1Answer := Part1_HowManyColorsCanContain(myBagColor)
-	part2Answer := Part2_NumberOfBagsContainedByBagColor(myBagColor)
+	s.Part(1, func(log *trace.Logger) (answer.Answer, error) {
+		result, err := part1(g, color, log)
+		return answer.Int(result), err
+	})
 
-	fmt.Printf("Part 1 Answer: %d\n", part1Answer)
-	fmt.Printf("Part 2 Answer: %d\n", part2Answer)
+	s.Part(2, func(log *trace.Logger) (answer.Answer, error) {
+		result, err := g.BagsInside(color)
+		return answer.Int(result), err
+	})
 
 	return nil
 }
 
-func readInput(r io.Reader) ([]string, error) {
-	var (
-		s     = bufio.NewScanner(r)
-		input []string
-	)
-	for s.Scan() {
-		line := s.Text()
-		input = append(input, line)
-	}
-	if err := s.Err(); err != nil {
-		return nil, err
+func readBagGraph(r io.Reader, log *trace.Logger) (*BagGraph, error) {
+	lines, err := input.Lines(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading lines: %w", err)
 	}
-	return input, nil
+	return ParseBagGraph(lines, log)
 }
 
-var containmentRules map[string]map[string]int
-
-var (
-	rulePat        = regexp.MustCompile(`^(.+) bags contain (.+)$`)
-	containablePat = regexp.MustCompile(`^(\d+) (.+) bags?\.?$`)
-)
-
-func parseRules(input []string) error {
-	containmentRules = make(map[string]map[string]int)
-	for i, line := range input {
-		match := rulePat.FindStringSubmatch(line)
-		if len(match) != 3 {
-			return fmt.Errorf("unexpected container rule format: %q", line)
-		}
-
-		containerColor := match[1]
-		sequence := i + 1
-
-		if strings.HasSuffix(line, "no other bags.") {
-			// Bags that cannot contain any other bags are 'leaf nodes" in the
-			// bag capacity tree. When they're encountered programmatically, it
-			// indicates the end of a recursion descention.
-			containmentRules[containerColor] = nil
-			trace("[%4d] rule: %s contains nothing\n", sequence, containerColor)
-			continue
-		}
-
-		containableStr := match[2]
-		containableStr = strings.Trim(containableStr, " ")
-		containableList := strings.Split(containableStr, ", ")
-		trace("[%4d] rule: %s contains %s\n", sequence, containerColor, containableStr)
-
-		for _, containable := range containableList {
-			color, capacity, err := parseCapacityForColor(containable)
+// part1 counts the colors of bag that can end up holding a bag of the given
+// color.
+func part1(g *BagGraph, color string, log *trace.Logger) (int, error) {
+	ancestors, err := g.Ancestors(color)
+	if err != nil {
+		return 0, err
+	}
+	if log.Enabled(trace.LevelDebug) {
+		for i, a := range ancestors {
+			path, err := g.Explain(a, color)
 			if err != nil {
-				return err
+				return 0, err
 			}
-			if _, ok := containmentRules[containerColor]; !ok {
-				containmentRules[containerColor] = make(map[string]int)
-			}
-			containmentRules[containerColor][color] = capacity
+			log.Debugf("[%4d] %s", i, strings.Join(path, " > "))
 		}
 	}
-	return nil
+	return len(ancestors), nil
 }
 
-func parseCapacityForColor(caStr string) (string, int, error) {
-	ruleParams := containablePat.FindStringSubmatch(caStr)
-	if len(ruleParams) != 3 {
-		return "", 0, fmt.Errorf("unexpected capacity format: %q", caStr)
-	}
-
-	numStr := ruleParams[1]
-	num, err := strconv.Atoi(numStr)
-	if err != nil {
-		return "", 0, fmt.Errorf("bad number %q in capacity: %w", numStr, err)
-	}
-
-	color := ruleParams[2]
-
-	return color, num, nil
+// rulesFlag defines the -rules flag that day 7's commands read the rules
+// with. Standard input is left for the queries.
+func rulesFlag(fs *flag.FlagSet) *string {
+	return fs.String("rules", "input.txt", "Read the rules from `path`.")
 }
 
-func Part1_HowManyColorsCanContain(targetColor string) int {
-	count := 0
-	for color, r := range containmentRules {
-		if color == targetColor {
-			// Bag of target color being able to hold bags of its own color
-			// would lead to infinite bags, so we'll skip that to not be silly.
-			continue
-		}
-		if canContain(targetColor, r) {
-			trace("[%4d] %q can contain %q", count, color, targetColor)
-			count++
-		}
+// readRules reads the bag rules from the file at path.
+func readRules(path string, log *trace.Logger) (*BagGraph, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
 	}
-	return count
+	defer f.Close()
+	return readBagGraph(f, log)
 }
 
-func canContain(targetColor string, containableBags map[string]int) bool {
-	isContainable := false
-	for color := range containableBags {
-		if color == targetColor {
-			return true
-		}
-		subContainable, ok := containmentRules[color]
-		if !ok {
-			// I would normally send an error back up instead of panic, but in
-			// this case cutting out excess error-handling simplifies the code
-			// enough--especially in Go, in these reecursive functions--to be
-			// worth it.
-			panic(fmt.Sprintf("unknown color %q", color))
-		}
-		isContainable = isContainable || canContain(targetColor, subContainable)
+// dot prints the DOT graph of the bags around the -color bag.
+func (sol *solver) dot(args []string, stdin io.Reader, stdout io.Writer, log *trace.Logger) error {
+	fs := flag.NewFlagSet("dot", flag.ContinueOnError)
+	rules := rulesFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
 	}
-	return isContainable
-}
-
-func Part2_NumberOfBagsContainedByBagColor(color string) int {
-	total := numberOfBagsForBagColor(color)
-
-	// Do not count the outermost bag! We cannot do this inside the recursively
-	// called function because it would misrepresent the number of bags nested.
-	// We'll just adjust it here for the single case.
-	numInside := total - 1
-
-	return numInside
-}
-
-func numberOfBagsForBagColor(color string) int {
-	containedBags, ok := containmentRules[color]
-	if !ok {
-		panic(fmt.Sprintf("unknown color %q", color))
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %q", fs.Args())
 	}
-	count := 1
-	for color, capacity := range containedBags {
-		numBagsInOneBag := numberOfBagsForBagColor(color)
-		count += capacity * numBagsInOneBag
+	g, err := readRules(*rules, log)
+	if err != nil {
+		return err
 	}
-	trace("One %q bag contains %d bags inside it", color, count-1)
-	return count
+	return g.WriteDOT(stdout, sol.color)
 }
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+var (
+	adjectives = []string{"light", "dark", "bright", "muted", "shiny", "faded", "dotted", "vibrant", "pale", "wavy"}
+	colors     = []string{"red", "orange", "white", "yellow", "gold", "olive", "plum", "blue", "black", "teal"}
+)
+
+// generate makes rules for size bag colors, one of them shiny gold. Bags only
+// contain bags that come later in the list, so that there are no cycles, and
+// at most two kinds of them, so that the totals stay reasonable.
+func generate(rng *rand.Rand, size int) []string {
+	if size < 2 {
+		size = 2
+	}
+	if max := len(adjectives) * len(colors); size > max {
+		size = max
+	}
+	var (
+		names = make([]string, 0, size)
+		seen  = map[string]bool{DefaultBagColor: true}
+	)
+	for len(names) < size-1 {
+		name := adjectives[rng.Intn(len(adjectives))] + " " + colors[rng.Intn(len(colors))]
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	gold := rng.Intn(size)
+	names = append(names[:gold], append([]string{DefaultBagColor}, names[gold:]...)...)
+
+	lines := make([]string, len(names))
+	for i, name := range names {
+		var contents []string
+		if later := len(names) - i - 1; later > 0 {
+			for _, j := range rng.Perm(later)[:rng.Intn(min(3, later+1))] {
+				n := 1 + rng.Intn(3)
+				noun := "bags"
+				if n == 1 {
+					noun = "bag"
+				}
+				contents = append(contents, fmt.Sprintf("%d %s %s", n, names[i+1+j], noun))
+			}
+		}
+		if len(contents) == 0 {
+			lines[i] = name + " bags contain no other bags."
+			continue
+		}
+		lines[i] = name + " bags contain " + strings.Join(contents, ", ") + "."
+	}
+	rng.Shuffle(len(lines), func(i, j int) { lines[i], lines[j] = lines[j], lines[i] })
+	return lines
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
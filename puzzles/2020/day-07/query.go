@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
+)
+
+// Usage says how to ask a kind of query.
+type Usage struct {
+	Query   string `json:"query"`
+	Summary string `json:"summary"`
+}
+
+// usages are the kinds of query, which the help query lists.
+var usages = []Usage{
+	{"containers COLOR", "every bag that can end up holding COLOR"},
+	{"contents COLOR [LEVELS]", "what COLOR holds, LEVELS deep (default 1)"},
+	{"total COLOR", "how many bags are inside COLOR altogether"},
+	{"depth [COLOR]", "how many levels of bags COLOR holds, or the most any bag does"},
+	{"unruled", "bags that are only ever inside others, with no rule of their own"},
+	{"help", "show this help"},
+	{"quit", "stop asking"},
+}
+
+// ErrQuit is returned for the quit query.
+var ErrQuit = errors.New("quit")
+
+// Querier answers questions about bag rules, as text or JSON.
+type Querier struct {
+	G    *BagGraph
+	JSON bool
+}
+
+// Deepest is the answer to a depth query.
+type Deepest struct {
+	Color string `json:"color"`
+	Depth int    `json:"depth"`
+}
+
+// queryResult is a line of JSON output.
+type queryResult struct {
+	Query  string      `json:"query"`
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// Answer works out the answer to a query. The result is a list of colors,
+// a list of Contents, a number, a Deepest, or a list of Usages for the help
+// query. The quit query returns ErrQuit.
+func (q *Querier) Answer(query string) (interface{}, error) {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return nil, errors.New("empty query")
+	}
+	color := strings.Join(fields[1:], " ")
+	needColor := func() error {
+		if color == "" {
+			return fmt.Errorf("%s needs a color", fields[0])
+		}
+		return nil
+	}
+
+	switch fields[0] {
+	case "containers":
+		if err := needColor(); err != nil {
+			return nil, err
+		}
+		colors, err := q.G.Ancestors(color)
+		if colors == nil {
+			colors = []string{}
+		}
+		return colors, err
+	case "contents":
+		levels := 1
+		if n := len(fields); n > 2 {
+			if l, err := strconv.Atoi(fields[n-1]); err == nil {
+				levels = l
+				color = strings.Join(fields[1:n-1], " ")
+			}
+		}
+		if err := needColor(); err != nil {
+			return nil, err
+		}
+		contents, err := q.G.Tree(color, levels)
+		if contents == nil {
+			contents = []Content{}
+		}
+		return contents, err
+	case "total":
+		if err := needColor(); err != nil {
+			return nil, err
+		}
+		return q.G.BagsInside(color)
+	case "depth":
+		if color == "" {
+			c, d := q.G.Deepest()
+			if d < 0 {
+				return nil, errors.New("there are no rules")
+			}
+			return Deepest{Color: c, Depth: d}, nil
+		}
+		d, err := q.G.Depth(color)
+		return Deepest{Color: color, Depth: d}, err
+	case "unruled":
+		colors := q.G.Unruled()
+		if colors == nil {
+			colors = []string{}
+		}
+		return colors, nil
+	case "help", "h":
+		return usages, nil
+	case "quit", "q":
+		return nil, ErrQuit
+	}
+	return nil, fmt.Errorf("unknown query %q; try help", fields[0])
+}
+
+// Write answers a query and writes the answer, or the error, to w.
+func (q *Querier) Write(w io.Writer, query string) error {
+	result, err := q.Answer(query)
+	return q.write(w, query, result, err)
+}
+
+// write writes the answer to a query, or the error in working it out, to w.
+func (q *Querier) write(w io.Writer, query string, result interface{}, err error) error {
+	if q.JSON {
+		r := queryResult{Query: query, Result: result}
+		if err != nil {
+			r = queryResult{Query: query, Error: err.Error()}
+		}
+		return json.NewEncoder(w).Encode(r)
+	}
+	if err != nil {
+		_, err = fmt.Fprintf(w, "error: %v\n", err)
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	switch result := result.(type) {
+	case []string:
+		if len(result) == 0 {
+			fmt.Fprintln(bw, "none")
+		}
+		for _, color := range result {
+			fmt.Fprintln(bw, color)
+		}
+	case []Content:
+		if len(result) == 0 {
+			fmt.Fprintln(bw, "nothing")
+		}
+		writeContents(bw, result, 0)
+	case Deepest:
+		levels := "levels"
+		if result.Depth == 1 {
+			levels = "level"
+		}
+		fmt.Fprintf(bw, "%d %s in %s bags\n", result.Depth, levels, result.Color)
+	case []Usage:
+		fmt.Fprintln(bw, "queries:")
+		for _, u := range result {
+			fmt.Fprintf(bw, "  %-23s %s\n", u.Query, u.Summary)
+		}
+	default:
+		fmt.Fprintln(bw, result)
+	}
+	return bw.Flush()
+}
+
+func writeContents(w io.Writer, contents []Content, indent int) {
+	for _, c := range contents {
+		fmt.Fprintf(w, "%s%d %s\n", strings.Repeat("  ", indent), c.Count, c.Color)
+		writeContents(w, c.Contents, indent+1)
+	}
+}
+
+// Run answers the queries read from r, one per line, until r runs out or the
+// quit query. Text output has a prompt before each query.
+func (q *Querier) Run(r io.Reader, w io.Writer) error {
+	s := bufio.NewScanner(r)
+	for {
+		if !q.JSON {
+			fmt.Fprint(w, "(bags) ")
+		}
+		if !s.Scan() {
+			if !q.JSON {
+				fmt.Fprintln(w)
+			}
+			return s.Err()
+		}
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			continue
+		}
+		result, err := q.Answer(line)
+		if errors.Is(err, ErrQuit) {
+			return nil
+		}
+		if err := q.write(w, line, result, err); err != nil {
+			return err
+		}
+	}
+}
+
+// query answers the query in args, or reads queries from stdin if there
+// isn't one, about the rules in the -rules file.
+func query(args []string, stdin io.Reader, stdout io.Writer, log *trace.Logger) error {
+	fs := flag.NewFlagSet("query", flag.ContinueOnError)
+	var (
+		rules  = rulesFlag(fs)
+		format = fs.String("format", "text", "Output `format`: text or json.")
+	)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	g, err := readRules(*rules, log)
+	if err != nil {
+		return err
+	}
+
+	q := &Querier{G: g, JSON: *format == "json"}
+	if fs.NArg() > 0 {
+		query := strings.Join(fs.Args(), " ")
+		result, err := q.Answer(query)
+		if errors.Is(err, ErrQuit) {
+			return nil
+		}
+		if werr := q.write(stdout, query, result, err); werr != nil {
+			return werr
+		}
+		// The error has been written, but the command still fails.
+		return err
+	}
+	return q.Run(stdin, stdout)
+}
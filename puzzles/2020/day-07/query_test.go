@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
+)
+
+func TestQuerier(t *testing.T) {
+	g := readSample(t, "sample-input.txt")
+	tt := []struct {
+		query string
+		json  bool
+		want  string
+	}{
+		{query: "containers bright white", want: "dark orange\nlight red\n"},
+		{query: "contents shiny gold 2", want: "1 dark olive\n  4 dotted black\n  3 faded blue\n2 vibrant plum\n  6 dotted black\n  5 faded blue\n"},
+		{query: "contents faded blue", want: "nothing\n"},
+		{query: "total shiny gold", want: "32\n"},
+		{query: "depth", want: "4 levels in dark orange bags\n"},
+		{query: "depth shiny gold", json: true, want: `{"query":"depth shiny gold","result":{"color":"shiny gold","depth":2}}` + "\n"},
+		{query: "unruled", json: true, want: `{"query":"unruled","result":[]}` + "\n"},
+		{query: "total", want: "error: total needs a color\n"},
+		{query: "containers plaid", json: true, want: `{"query":"containers plaid","error":"unknown color \"plaid\""}` + "\n"},
+	}
+	for _, tc := range tt {
+		var out strings.Builder
+		q := &Querier{G: g, JSON: tc.json}
+		if err := q.Write(&out, tc.query); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if out.String() != tc.want {
+			t.Errorf("%s: expected %q, but got %q", tc.query, tc.want, out.String())
+		}
+	}
+}
+
+func TestQuerierRun(t *testing.T) {
+	g := readSample(t, "sample-input.txt")
+	var out strings.Builder
+	q := &Querier{G: g, JSON: true}
+	if err := q.Run(strings.NewReader("total shiny gold\n\nhelp\nquit\ntotal faded blue\n"), &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 answers before quitting, but got %d: %q", len(lines), lines)
+	}
+	if want := `{"query":"total shiny gold","result":32}`; lines[0] != want {
+		t.Errorf("expected %q, but got %q", want, lines[0])
+	}
+	var help struct {
+		Query  string  `json:"query"`
+		Result []Usage `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &help); err != nil {
+		t.Fatalf("help isn't JSON: %v", err)
+	}
+	if len(help.Result) != len(usages) {
+		t.Errorf("expected %d usages, but got %d", len(usages), len(help.Result))
+	}
+}
+
+func TestQueryCommand(t *testing.T) {
+	tt := []struct {
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{args: []string{"total", "shiny", "gold"}, want: "32\n"},
+		{args: []string{"-format", "json", "total", "shiny", "gold"}, want: `{"query":"total shiny gold","result":32}` + "\n"},
+		{args: []string{"-format", "json", "total"}, want: `{"query":"total","error":"total needs a color"}` + "\n", wantErr: true},
+	}
+	for _, tc := range tt {
+		var out strings.Builder
+		args := append([]string{"-rules", "sample-input.txt"}, tc.args...)
+		err := query(args, strings.NewReader(""), &out, trace.Discard)
+		if gotErr := err != nil; gotErr != tc.wantErr {
+			t.Errorf("%q: expected error: %t, but got %v", tc.args, tc.wantErr, err)
+		}
+		if out.String() != tc.want {
+			t.Errorf("%q: expected %q, but got %q", tc.args, tc.want, out.String())
+		}
+	}
+}
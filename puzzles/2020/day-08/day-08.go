@@ -3,211 +3,202 @@
 package main
 
 import (
-	"bufio"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
-	"log"
 	"os"
-	"strconv"
-	"strings"
-)
 
-var verbose bool
+	"github.com/ianfoo/advent-of-code-2020/internal/answer"
+	"github.com/ianfoo/advent-of-code-2020/internal/console"
+	"github.com/ianfoo/advent-of-code-2020/internal/input"
+	"github.com/ianfoo/advent-of-code-2020/internal/runner"
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
+)
 
 func main() {
-	flag.BoolVar(&verbose, "verbose", false, "Print more detail.")
-	flag.Parse()
-
-	log.SetFlags(log.Ltime | log.Lmicroseconds)
-
-	if err := run(os.Stdin); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
-	}
-}
-
-func trace(format string, params ...interface{}) {
-	if verbose {
-		log.Printf(format, params...)
-	}
+	runner.Solution{
+		Year:     2020,
+		Day:      8,
+		Solve:    run,
+		Generate: generate,
+		Commands: map[string]runner.Command{
+			"debug": {
+				Args:    "[file]",
+				Summary: "Step through the program in file (default input.txt) with an interactive debugger.",
+				Run:     debug,
+			},
+			"analyze": {
+				Args:    "[-format dot|text] [file]",
+				Summary: "Print the control flow graph of the program in file (default standard input), its loops, unreachable and out of range instructions, and the swaps that fix it.",
+				Run:     analyze,
+			},
+		},
+	}.Main()
 }
 
-func run(r io.Reader) error {
-	input, err := readInput(r)
+func run(r io.Reader, s *runner.Session) error {
+	prog, err := readProgram(r)
 	if err != nil {
-		return fmt.Errorf("reading input: %w", err)
+		return err
 	}
+	s.Log.Debugf("read %d instructions", len(prog))
 
-	instr := make([]Instruction, 0, len(input))
-	for i, line := range input {
-		current, err := parseInstruction(line)
-		if err != nil {
-			return err
-		}
-		instr = append(instr, current)
-		trace("[%4d] instruction: %v", i, current)
-	}
+	s.Part(1, func(log *trace.Logger) (answer.Answer, error) {
+		result, err := part1(prog, log)
+		return answer.Int(result), err
+	})
 
-	{
-		result, err := part1(instr)
-		if err != nil {
-			return fmt.Errorf("part 1: %w", err)
-		}
-		fmt.Printf("Part 1: %d\n", result)
-	}
+	s.Part(2, func(log *trace.Logger) (answer.Answer, error) {
+		result, err := part2(prog, log)
+		return answer.Int(result), err
+	})
 
-	{
-		result, err := part2(instr)
-		if err != nil {
-			return fmt.Errorf("part 2: %w", err)
+	s.Impl(2, "brute-force", func(log *trace.Logger) (answer.Answer, error) {
+		if len(prog) > maxBruteForceSize {
+			return answer.Answer{}, fmt.Errorf("brute force would rerun a program of %d instructions: %w", len(prog), runner.ErrSkip)
 		}
-		fmt.Printf("Part 2: %d\n", result)
-	}
+		result, err := part2BruteForce(prog, log)
+		return answer.Int(result), err
+	})
 
 	return nil
 }
 
-func part1(instr []Instruction) (int, error) {
-	acc, err := executeInstructions(instr)
-	return acc, err
-}
-
-func part2(instr []Instruction) (int, error) {
-	return executeAndModifyInstructions(instr)
-}
-
-func readInput(r io.Reader) ([]string, error) {
-	var (
-		s     = bufio.NewScanner(r)
-		input []string
-	)
-	for s.Scan() {
-		line := s.Text()
-		input = append(input, line)
+func readProgram(r io.Reader) (console.Program, error) {
+	lines, err := input.Lines(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading lines: %w", err)
 	}
-	if err := s.Err(); err != nil {
-		return nil, err
+	prog, err := console.Parse(lines)
+	if err != nil {
+		return nil, fmt.Errorf("parsing program: %w", err)
 	}
-	return input, nil
-}
-
-type Instruction struct {
-	Op  string
-	Arg int
+	return prog, nil
 }
 
-func parseInstruction(instrStr string) (Instruction, error) {
-	tokens := strings.Split(instrStr, " ")
-	if len(tokens) != 2 {
-		return Instruction{}, fmt.Errorf("invalid instruction %q", instrStr)
-	}
-
-	op := tokens[0]
-	arg, err := strconv.Atoi(tokens[1])
+// part1 finds the value of the accumulator just before the program runs an
+// instruction for the second time.
+func part1(prog console.Program, log *trace.Logger) (int, error) {
+	m := console.New(prog)
+	m.Log = log
+	res, err := m.Run()
 	if err != nil {
-		return Instruction{}, fmt.Errorf("invalid instruction argument %q: %w", instrStr, err)
+		return 0, err
 	}
-
-	instr := Instruction{
-		Op:  op,
-		Arg: arg,
+	if res.Outcome != console.Looped {
+		return 0, fmt.Errorf("expected the program to loop, but it ended: %v", res.Outcome)
 	}
-	return instr, nil
-}
-
-func exec(instr Instruction, prgCtr, acc int) (int, int) {
-	trace("running instruction %v", instr)
-	switch instr.Op {
-	case "nop":
-		prgCtr++
-	case "acc":
-		acc += instr.Arg
-		prgCtr++
-	case "jmp":
-		prgCtr += instr.Arg
-	}
-	return prgCtr, acc
+	return res.State.Acc, nil
 }
 
-func executeInstructions(instr []Instruction) (int, error) {
-	var (
-		acc        = 0
-		prgCtr     = 0
-		runHistory = make([]bool, len(instr))
-	)
-	for !runHistory[prgCtr] {
-		inst := instr[prgCtr]
-		runHistory[prgCtr] = true
-		prgCtr, acc = exec(inst, prgCtr, acc)
-	}
-	return acc, nil
+// part2 finds the jmp or nop that needs to be swapped for the other to make
+// the program halt, and returns the accumulator it halts with.
+func part2(prog console.Program, log *trace.Logger) (int, error) {
+	patch, res, err := console.Repair(prog)
+	if err != nil {
+		return 0, err
+	}
+	log.Infof("patched %v", patch)
+	if res.Outcome != console.Halted {
+		return 0, fmt.Errorf("expected the patched program to halt, but it ended: %v", res.Outcome)
+	}
+	return res.State.Acc, nil
 }
 
-func executeAndModifyInstructions(instr []Instruction) (int, error) {
-	var (
-		acc    = 0
-		prgCtr = 0
-
-		runHistory    = make([]bool, len(instr))
-		revisionPoint = 0
-	)
-
-	findAndChangeNextCandidate := func(index int) ([]Instruction, int) {
-		workingCopy := make([]Instruction, len(instr))
-		copy(workingCopy, instr)
-
-		for ; index < len(workingCopy); index++ {
-			if next := workingCopy[index]; next.Op == "jmp" || next.Op == "nop" {
-				trace("found candidate %v at index %d", next, index)
-				break
-			}
+// maxBruteForceSize is the longest program part2BruteForce will try to fix.
+const maxBruteForceSize = 10000
+
+// part2BruteForce finds the same answer as part2 by swapping each jmp for a
+// nop, or nop for a jmp, and running the whole program again each time, until
+// it halts.
+func part2BruteForce(prog console.Program, log *trace.Logger) (int, error) {
+	swapped := map[string]string{"jmp": "nop", "nop": "jmp"}
+	patched := prog.Clone()
+	for i, in := range prog {
+		op, ok := swapped[in.Op]
+		if !ok {
+			continue
 		}
-
-		// We have reached the end of the instructions.
-		if index == len(workingCopy) {
-			return workingCopy, index
+		patched[i].Op = op
+		res, err := console.Run(patched)
+		patched[i].Op = in.Op
+		if err != nil {
+			return 0, err
 		}
-
-		if workingCopy[index].Op == "nop" {
-			workingCopy[index].Op = "jmp"
-		} else if workingCopy[index].Op == "jmp" {
-			workingCopy[index].Op = "nop"
+		log.Debugf("swapping %v at %d: %v after %d steps", in, i, res.Outcome, res.Steps)
+		if res.Outcome == console.Halted {
+			return res.State.Acc, nil
 		}
+	}
+	return 0, errors.New("no single swap makes the program halt")
+}
 
-		// Move the index past the point that was just changed, so we don't
-		// repeat this forever.
-		index++
-
-		trace("%v", workingCopy)
-		return workingCopy, index
+// debug runs the interactive debugger on the program in the file named by
+// args, reading commands from stdin.
+func debug(args []string, stdin io.Reader, stdout io.Writer, log *trace.Logger) error {
+	path := "input.txt"
+	switch len(args) {
+	case 0:
+	case 1:
+		path = args[0]
+	default:
+		return errors.New("expected at most one file")
 	}
 
-	resetProgram := func() {
-		prgCtr = 0
-		acc = 0
-		runHistory = make([]bool, len(instr))
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	prog, err := readProgram(f)
+	if err != nil {
+		return err
 	}
 
-	var workingCopy = instr
-	for prgCtr != len(instr) {
-		if runHistory[prgCtr] {
-			trace("cycle dectected on instruction %d: %v", prgCtr, workingCopy[prgCtr])
+	m := console.New(prog)
+	m.Log = log
+	return console.NewDebugger(m).Run(stdin, stdout)
+}
 
-			if revisionPoint >= len(instr) {
-				return 0, errors.New("no more candidate instructions to try")
-			}
-			workingCopy, revisionPoint = findAndChangeNextCandidate(revisionPoint)
+// analyze prints what console.Analyze works out about the program in the file
+// named by args, or standard input.
+func analyze(args []string, stdin io.Reader, stdout io.Writer, log *trace.Logger) error {
+	fs := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	format := fs.String("format", "dot", "Output `format`: dot or text.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != "dot" && *format != "text" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
 
-			resetProgram()
-			continue
+	r := stdin
+	switch fs.NArg() {
+	case 0:
+	case 1:
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
 		}
+		defer f.Close()
+		r = f
+	default:
+		return errors.New("expected at most one file")
+	}
+	prog, err := readProgram(r)
+	if err != nil {
+		return err
+	}
 
-		inst := workingCopy[prgCtr]
-		runHistory[prgCtr] = true
-
-		prgCtr, acc = exec(inst, prgCtr, acc)
+	a, err := console.Analyze(prog)
+	if err != nil {
+		return err
+	}
+	log.Infof("%v; %d unreachable, %d loops, %d out of range, %d fixes",
+		a.Outcome, len(a.Unreachable()), len(a.Loops), len(a.OutOfRange), len(a.Fixes))
+	if *format == "text" {
+		return a.WriteReport(stdout)
 	}
-	return acc, nil
+	return a.WriteDOT(stdout)
 }
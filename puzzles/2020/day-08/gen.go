@@ -0,0 +1,65 @@
+package main
+
+import (
+	"math/rand"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/console"
+)
+
+// generate makes a program of size instructions that loops forever, but that
+// terminates if exactly one jmp or nop is swapped for the other, just like
+// the real input. Every jump, including the ones a nop would make if it were
+// swapped, lands inside the program or just past its end.
+func generate(rng *rand.Rand, size int) []string {
+	if size < 2 {
+		size = 2
+	}
+	for {
+		prog := make(console.Program, size)
+		for i := range prog {
+			// Jump forward by default, so that the program terminates until
+			// it's broken below.
+			arg := 1 + rng.Intn(min(3, size-i))
+			switch rng.Intn(3) {
+			case 0:
+				prog[i] = console.Instruction{Op: "acc", Arg: rng.Intn(100) - 50}
+			case 1:
+				prog[i] = console.Instruction{Op: "nop", Arg: arg - rng.Intn(i+1)}
+			default:
+				prog[i] = console.Instruction{Op: "jmp", Arg: arg}
+			}
+		}
+
+		// Break the program by sending one of the instructions it runs back
+		// to where it has already been.
+		path, _ := simulate(prog)
+		i := path[rng.Intn(len(path))]
+		prog[i] = console.Instruction{Op: "jmp", Arg: -rng.Intn(i + 1)}
+		if fixes, err := console.Repairs(prog); err != nil || len(fixes) != 1 {
+			continue
+		}
+
+		lines := make([]string, len(prog))
+		for i, in := range prog {
+			lines[i] = in.String()
+		}
+		return lines
+	}
+}
+
+// simulate runs a program until it terminates or runs an instruction for the
+// second time, and returns the instructions it ran.
+func simulate(prog console.Program) ([]int, bool) {
+	var path []int
+	m := console.New(prog)
+	m.Trace = func(s console.Step) { path = append(path, s.Before.PC) }
+	res, err := m.Run()
+	return path, err == nil && res.Outcome == console.Halted
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
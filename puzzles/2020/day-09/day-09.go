@@ -3,82 +3,57 @@
 package main
 
 import (
-	"bufio"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
-	"log"
 	"math"
-	"os"
-	"strconv"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/answer"
+	"github.com/ianfoo/advent-of-code-2020/internal/input"
+	"github.com/ianfoo/advent-of-code-2020/internal/runner"
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
 )
 
 // Sample data uses differnet value, so allow it to be changed.
 const DefaultCypherSize = 25
 
-var (
-	cypherSize int
-	verbose    bool
-)
+var cypherSize int
 
 func main() {
-	flag.BoolVar(&verbose, "verbose", false, "Print more detail.")
 	flag.IntVar(&cypherSize, "cypher-size", DefaultCypherSize, "Change size of cypher.")
-	flag.Parse()
-
-	log.SetFlags(log.Ltime | log.Lmicroseconds)
-
-	if err := run(os.Stdin, cypherSize); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
-	}
+	runner.Solution{
+		Year: 2020,
+		Day:  9,
+		Solve: func(r io.Reader, s *runner.Session) error {
+			return run(r, cypherSize, s)
+		},
+		Generate: generate,
+	}.Main()
 }
 
-func trace(format string, params ...interface{}) {
-	if verbose {
-		log.Printf(format, params...)
-	}
-}
-
-func run(r io.Reader, cypherSize int) error {
-	input, err := readInput(r)
-	if err != nil {
-		return fmt.Errorf("reading input: %w", err)
-	}
-
-	invalidSum, err := part1(input, cypherSize)
+func run(r io.Reader, cypherSize int, s *runner.Session) error {
+	nums, err := input.Ints(r)
 	if err != nil {
-		return fmt.Errorf("part 1: %w", err)
+		return fmt.Errorf("reading nums: %w", err)
 	}
-	fmt.Printf("Part 1: %d\n", invalidSum)
 
-	result, err := part2(input, invalidSum)
-	if err != nil {
-		return fmt.Errorf("part 2: %w", err)
-	}
-	fmt.Printf("Part 2: %d\n", result)
-
-	return nil
-}
+	s.Part(1, func(log *trace.Logger) (answer.Answer, error) {
+		result, err := part1(nums, cypherSize, log)
+		return answer.Int(result), err
+	})
 
-func readInput(r io.Reader) ([]int, error) {
-	var (
-		s     = bufio.NewScanner(r)
-		input []int
-	)
-	for s.Scan() {
-		line := s.Text()
-		n, err := strconv.Atoi(line)
+	s.Part(2, func(log *trace.Logger) (answer.Answer, error) {
+		// Part 2 looks for numbers adding up to the answer to part 1.
+		invalidSum, err := part1(nums, cypherSize, trace.Discard)
 		if err != nil {
-			return nil, fmt.Errorf("invalid input: %w", err)
+			return answer.Answer{}, err
 		}
-		input = append(input, n)
-	}
-	if err := s.Err(); err != nil {
-		return nil, err
-	}
-	return input, nil
+		result, err := part2(nums, invalidSum)
+		return answer.Int(result), err
+	})
+
+	return nil
 }
 
 func isValid(window []int, targetSum int) bool {
@@ -93,8 +68,8 @@ func isValid(window []int, targetSum int) bool {
 	return false
 }
 
-func part1(input []int, cypherSize int) (int, error) {
-	trace("input: %v", input)
+func part1(input []int, cypherSize int, log *trace.Logger) (int, error) {
+	log.Tracef("input: %v", input)
 	for i := cypherSize; i < len(input); i++ {
 
 		// Define window of input for building sums.
@@ -103,11 +78,11 @@ func part1(input []int, cypherSize int) (int, error) {
 			upper = i
 		)
 		window := input[lower : upper : cypherSize+lower]
-		trace("window: elements %d-%d: %v", lower, upper, window)
+		log.Tracef("window: elements %d-%d: %v", lower, upper, window)
 
 		// Check validity of number following window.
 		if n := input[i]; !isValid(window, n) {
-			trace("%d on line %d is not a sum of two entries in window", n, i+1)
+			log.Debugf("%d on line %d is not a sum of two entries in window", n, i+1)
 			return n, nil
 		}
 	}
@@ -127,12 +102,11 @@ func part2(input []int, targetSum int) (int, error) {
 	minAndMax := func(numbers []int) (int, int) {
 		var (
 			min = math.MaxInt64
-			max = 0
+			max = math.MinInt64
 		)
 		for _, x := range numbers {
 			if x < min {
 				min = x
-				continue
 			}
 			if x > max {
 				max = x
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"math/rand"
+	"strconv"
+)
+
+// generate makes a preamble of cypherSize numbers followed by size numbers
+// that are each the sum of two different numbers in the window before them,
+// and then one that isn't, but is the sum of a run of earlier numbers.
+func generate(rng *rand.Rand, size int) []string {
+	for {
+		nums := rng.Perm(cypherSize * 2)[:cypherSize]
+		for i := range nums {
+			nums[i]++
+		}
+		for len(nums) < cypherSize+size {
+			window := nums[len(nums)-cypherSize:]
+			x, y := window[rng.Intn(cypherSize)], window[rng.Intn(cypherSize)]
+			if x != y {
+				nums = append(nums, x+y)
+			}
+		}
+
+		var (
+			start = rng.Intn(len(nums) - 2)
+			end   = start + 2 + rng.Intn(min(5, len(nums)-start-2)+1)
+			sum   int
+		)
+		for _, n := range nums[start:end] {
+			sum += n
+		}
+		if isValid(nums[len(nums)-cypherSize:], sum) {
+			continue
+		}
+		nums = append(nums, sum)
+
+		lines := make([]string, len(nums))
+		for i, n := range nums {
+			lines[i] = strconv.Itoa(n)
+		}
+		return lines
+	}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -3,98 +3,65 @@
 package main
 
 import (
-	"bufio"
 	"errors"
-	"flag"
 	"fmt"
 	"io"
-	"log"
-	"os"
 	"sort"
-	"strconv"
-)
 
-var verbose bool
+	"github.com/ianfoo/advent-of-code-2020/internal/answer"
+	"github.com/ianfoo/advent-of-code-2020/internal/input"
+	"github.com/ianfoo/advent-of-code-2020/internal/runner"
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
+)
 
 func main() {
-	flag.BoolVar(&verbose, "verbose", false, "Print more detail.")
-	flag.Parse()
-
-	log.SetFlags(log.Ltime | log.Lmicroseconds)
-
-	if err := run(os.Stdin); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
-	}
+	runner.Solution{Year: 2020, Day: 10, Solve: run, Generate: generate}.Main()
 }
 
-func trace(format string, params ...interface{}) {
-	if verbose {
-		fmt.Printf(format+"\n", params...)
-	}
-}
-
-func run(r io.Reader) error {
-	input, err := readInput(r)
+func run(r io.Reader, s *runner.Session) error {
+	adapters, err := input.Ints(r)
 	if err != nil {
-		return fmt.Errorf("reading input: %w", err)
-	}
-
-	{
-		result, err := part1(input)
-		if err != nil {
-			return fmt.Errorf("part 1: %w", err)
-		}
-		fmt.Printf("Part 1: %d\n", result)
-	}
-
-	{
-		result, err := part2(input)
-		if err != nil {
-			return fmt.Errorf("part 2: %w", err)
-		}
-		fmt.Printf("Part 2: %d\n", result)
-	}
+		return fmt.Errorf("reading adapters: %w", err)
+	}
+
+	s.Part(1, func(log *trace.Logger) (answer.Answer, error) {
+		result, err := part1(adapters, log)
+		return answer.Int(result), err
+	})
+
+	s.Part(2, func(log *trace.Logger) (answer.Answer, error) {
+		result, err := part2(adapters, log)
+		return answer.Int(result), err
+	})
+	s.Impl(2, "dp", func(log *trace.Logger) (answer.Answer, error) {
+		result, err := part2DP(adapters, log)
+		return answer.Int(result), err
+	})
+	s.Impl(2, "tracker", func(log *trace.Logger) (answer.Answer, error) {
+		result, err := part2Tracker(adapters, log)
+		return answer.Int(result), err
+	})
 
 	return nil
 }
 
-func readInput(r io.Reader) ([]int, error) {
-	var (
-		s     = bufio.NewScanner(r)
-		input []int
-	)
-	for s.Scan() {
-		line := s.Text()
-		n, err := strconv.Atoi(line)
-		if err != nil {
-			return nil, fmt.Errorf("invalid input %s: %w", line, err)
-		}
-		input = append(input, n)
-	}
-	if err := s.Err(); err != nil {
-		return nil, err
-	}
-	return input, nil
-}
-
 // Add built-in adapter to list of adapters. Need to know max value first, so
 // sort them here.
-func sortAndAddDeviceAdapter(adapters []int) []int {
+func sortAndAddDeviceAdapter(adapters []int, log *trace.Logger) []int {
 	sort.Ints(adapters)
 
 	max := adapters[len(adapters)-1]
 	builtInAdapter := max + 3
 	adapters = append(adapters, builtInAdapter)
 
-	trace("adapters: %v", adapters)
+	log.Debugf("adapters: %v", adapters)
 	return adapters
 }
 
 // Returns a histogram of differences, where index of the slice is equal to
 // difference. An error is returned if the adapters can not be connected to
 // reach final joltage.
-func canConnect(adapters []int) ([]int, bool) {
+func canConnect(adapters []int, log *trace.Logger) ([]int, bool) {
 	const maxDiff = 3
 	var (
 		// Histogram of differences, where index is equal to difference.
@@ -112,7 +79,7 @@ func canConnect(adapters []int) ([]int, bool) {
 		}
 		diffs[diff]++
 		currentJoltage = outputJolts
-		trace(
+		log.Tracef(
 			"%3d current + %d diff => %3d output diffs=%v",
 			currentJoltage, diff, outputJolts, diffs)
 	}
@@ -120,9 +87,9 @@ func canConnect(adapters []int) ([]int, bool) {
 	return diffs, true
 }
 
-func part1(adapters []int) (int, error) {
-	adapters = sortAndAddDeviceAdapter(adapters)
-	diffs, connects := canConnect(adapters)
+func part1(adapters []int, log *trace.Logger) (int, error) {
+	adapters = sortAndAddDeviceAdapter(adapters, log)
+	diffs, connects := canConnect(adapters, log)
 	if !connects {
 		return 0, errors.New("adapters cannot connect")
 	}
@@ -150,14 +117,12 @@ type Tracker map[int][][]int
 
 func (t Tracker) Add(a []int) {
 	if t.Exists(a) {
-		trace("exists in tracker: not adding: %v", a)
 		return
 	}
 	key := a[0]
 	val := t[key]
 	val = append(val, a)
 	t[key] = val
-	trace("t[%d] = %v", key, val)
 }
 
 func (t Tracker) Exists(a []int) bool {
@@ -186,30 +151,19 @@ func (t Tracker) CountAll() int {
 	return count
 }
 
-func countChainsIter(a []int) int {
-	finalStage := a[len(a)-1]
-	if !connected(a, finalStage) {
-		return 0
-	}
+// countChainsDP counts the arrangements from the bottom up: the number of
+// ways to reach an adapter is the sum of the ways to reach the adapters up to
+// three jolts below it.
+func countChainsDP(a []int, log *trace.Logger) int {
 	const maxDiff = 3
-	t := make(Tracker)
-	for i := 0; i < len(a)-1; i++ {
-		for j := i + 1; j < len(a); j++ {
-			if a[j]-a[i] >= maxDiff {
-				break
-			}
-			b := make([]int, j-1, len(a)-1)
-			copy(b, a[:i])
-			b = append(b, a[j+1:]...)
-			if !connected(b, finalStage) {
-				break
-			}
-			trace("valid: i=%d j=%d %v %v", a[i], a[j], a[:j], a[j+1:])
-			trace("adding b: %v", b)
-			t.Add(b)
+	ways := map[int]int{0: 1}
+	for _, n := range a {
+		for d := 1; d <= maxDiff; d++ {
+			ways[n] += ways[n-d]
 		}
+		log.Tracef("%3d: %d ways", n, ways[n])
 	}
-	return t.CountAll()
+	return ways[a[len(a)-1]]
 }
 
 func count(adapters map[int]struct{}, joules, finalStage int, memo map[int]int) int {
@@ -230,46 +184,61 @@ func count(adapters map[int]struct{}, joules, finalStage int, memo map[int]int)
 	return sum
 }
 
-func countChains(a []int, finalStage int, tracker Tracker) int {
+func countChains(a []int, finalStage int, tracker Tracker, log *trace.Logger) int {
 	if tracker.Exists(a) {
-		trace("already seen: %v", a)
+		log.Tracef("already seen: %v", a)
 		return 0
 	}
-	trace("checking connection for: %v", a)
+	log.Tracef("checking connection for: %v", a)
 	if !connected(a, finalStage) {
-		trace("terminal condition: %v does not connect", a)
+		log.Tracef("terminal condition: %v does not connect", a)
 		return 0
 	}
 
-	trace("ADDING ONE: %v", a)
+	log.Tracef("adding one: %v", a)
 	tracker.Add(a)
 	count := 1
 	for i := 0; i < len(a)-1; i++ {
-		trace("about to remove element at index %d: %v", i, a)
+		log.Tracef("about to remove element at index %d: %v", i, a)
 		b := make([]int, len(a))
 		copy(b, a)
 		b = append(b[:i], b[i+1:]...)
-		trace("removed element: len(a)=%d, len(b)=%d", len(a), len(b))
-		count += countChains(b, finalStage, tracker)
+		log.Tracef("removed element: len(a)=%d, len(b)=%d", len(a), len(b))
+		count += countChains(b, finalStage, tracker, log)
 	}
 	return count
 }
 
-func part2(adapters []int) (int, error) {
-	adapters = sortAndAddDeviceAdapter(adapters)
+func part2(adapters []int, log *trace.Logger) (int, error) {
+	adapters = sortAndAddDeviceAdapter(adapters, log)
 	finalStage := adapters[len(adapters)-1]
+	return countMemo(adapters, finalStage), nil
+}
 
+func countMemo(adapters []int, finalStage int) int {
 	adapterMap := make(map[int]struct{}, len(adapters))
 	for _, v := range adapters {
 		adapterMap[v] = struct{}{}
 	}
-
 	memo := make(map[int]int)
-	numChains := count(adapterMap, 0, finalStage, memo)
+	return count(adapterMap, 0, finalStage, memo)
+}
 
-	// tracker := make(Tracker)
-	// trace("part 2: adapters: %v", adapters)
-	// numChains := countChains(adapters, finalStage, tracker)
+func part2DP(adapters []int, log *trace.Logger) (int, error) {
+	adapters = sortAndAddDeviceAdapter(adapters, log)
+	return countChainsDP(adapters, log), nil
+}
+
+// The tracker remembers every arrangement it has seen, which takes several
+// seconds for the larger sample and would never finish for the real input.
+const maxTrackedChains = 10000
 
-	return numChains, nil
+func part2Tracker(adapters []int, log *trace.Logger) (int, error) {
+	adapters = sortAndAddDeviceAdapter(adapters, log)
+	finalStage := adapters[len(adapters)-1]
+	if n := countMemo(adapters, finalStage); n > maxTrackedChains {
+		return 0, fmt.Errorf("too many arrangements to track (%d): %w", n, runner.ErrSkip)
+	}
+	tracker := make(Tracker)
+	return countChains(adapters, finalStage, tracker, log), nil
 }
@@ -1,6 +1,14 @@
 package main
 
-import "testing"
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/input"
+	"github.com/ianfoo/advent-of-code-2020/internal/runner"
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
+)
 
 func TestTracker(t *testing.T) {
 	a := []int{4, 6, 9, 10}
@@ -24,3 +32,53 @@ func TestTracker(t *testing.T) {
 		t.Errorf("expected count of 2, but got %d", c)
 	}
 }
+
+func TestPart2Impls(t *testing.T) {
+	tt := []struct {
+		file string
+		want int
+	}{
+		{file: "sample-input-small.txt", want: 8},
+		{file: "sample-input.txt", want: 19208},
+	}
+	impls := []struct {
+		name string
+		fn   func([]int, *trace.Logger) (int, error)
+	}{
+		{"memo", part2},
+		{"dp", part2DP},
+		{"tracker", part2Tracker},
+	}
+	for _, tc := range tt {
+		for _, impl := range impls {
+			t.Run(tc.file+"/"+impl.name, func(t *testing.T) {
+				f, err := os.Open(tc.file)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				defer f.Close()
+				adapters, err := input.Ints(f)
+				if err != nil {
+					t.Fatalf("unexpected error reading input: %v", err)
+				}
+				got, err := impl.fn(adapters, trace.Discard)
+				if errors.Is(err, runner.ErrSkip) {
+					t.Skip(err)
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if got != tc.want {
+					t.Errorf("expected %d, but got %d", tc.want, got)
+				}
+			})
+		}
+	}
+}
+
+func TestDiffTest(t *testing.T) {
+	sol := runner.Solution{Solve: run, Generate: generate}
+	if f := sol.DiffTest(runner.DiffOptions{Runs: 50, Size: 12, Seed: 1}); f != nil {
+		t.Fatalf("%v\n%v", f, f.Input)
+	}
+}
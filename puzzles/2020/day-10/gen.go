@@ -0,0 +1,22 @@
+package main
+
+import (
+	"math/rand"
+	"strconv"
+)
+
+// generate makes size adapters that connect from the outlet to the device, in
+// random order. Most steps are one or three jolts, like the real input.
+func generate(rng *rand.Rand, size int) []string {
+	var (
+		steps   = []int{1, 1, 1, 1, 1, 1, 2, 3, 3, 3}
+		joltage int
+		lines   = make([]string, size)
+	)
+	for i := range lines {
+		joltage += steps[rng.Intn(len(steps))]
+		lines[i] = strconv.Itoa(joltage)
+	}
+	rng.Shuffle(len(lines), func(i, j int) { lines[i], lines[j] = lines[j], lines[i] })
+	return lines
+}
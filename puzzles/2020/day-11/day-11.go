@@ -3,231 +3,125 @@
 package main
 
 import (
-	"bufio"
 	"errors"
-	"flag"
 	"fmt"
 	"io"
-	"log"
-	"os"
-	"strings"
-)
 
-var verbose bool
+	"github.com/ianfoo/advent-of-code-2020/internal/answer"
+	"github.com/ianfoo/advent-of-code-2020/internal/automaton"
+	"github.com/ianfoo/advent-of-code-2020/internal/grid"
+	"github.com/ianfoo/advent-of-code-2020/internal/input"
+	"github.com/ianfoo/advent-of-code-2020/internal/runner"
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
+)
 
 func main() {
-	flag.BoolVar(&verbose, "verbose", false, "Print more detail.")
-	flag.Parse()
-
-	log.SetFlags(log.Ltime | log.Lmicroseconds)
-
-	if err := run(os.Stdin); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
-	}
+	runner.Solution{Year: 2020, Day: 11, Solve: run, Generate: generate}.Main()
 }
 
-func trace(format string, params ...interface{}) {
-	if verbose {
-		fmt.Printf(format+"\n", params...)
+func run(r io.Reader, s *runner.Session) error {
+	rows, err := input.Grid(r)
+	if err != nil {
+		return fmt.Errorf("reading seats: %w", err)
 	}
-}
-
-func run(r io.Reader) error {
-	input, err := readInput(r)
+	seats, err := grid.FromRunes(rows)
 	if err != nil {
-		return fmt.Errorf("reading input: %w", err)
+		return fmt.Errorf("reading seats: %w", err)
 	}
 
-	var part1Result int
-	{
-		var err error
-		part1Result, err = part1(input)
-		if err != nil {
-			return fmt.Errorf("part 1: %w", err)
-		}
-	}
+	s.Part(1, func(log *trace.Logger) (answer.Answer, error) {
+		result, err := part1(seats, log)
+		return answer.Int(result), err
+	})
 
-	var part2Result int
-	{
-		var err error
-		part2Result, err = part2(input)
-		if err != nil {
-			return fmt.Errorf("part 2: %w", err)
-		}
-	}
+	s.Part(2, func(log *trace.Logger) (answer.Answer, error) {
+		result, err := part2(seats, log)
+		return answer.Int(result), err
+	})
 
-	fmt.Printf("Part 1: %d\n", part1Result)
-	fmt.Printf("Part 2: %d\n", part2Result)
 	return nil
 }
 
-func readInput(r io.Reader) ([]string, error) {
-	var (
-		s     = bufio.NewScanner(r)
-		input []string
-	)
-	for s.Scan() {
-		line := s.Text()
-		input = append(input, line)
-	}
-	if err := s.Err(); err != nil {
-		return nil, err
+// seatRule returns the rule for how people choose seats: an empty seat with
+// no occupied neighbors fills up, and an occupied seat empties once
+// tooCrowdedThreshold or more of its neighbors are occupied. The floor never
+// changes.
+func seatRule(tooCrowdedThreshold int) automaton.Rule {
+	return func(seat rune, neighbors []rune) rune {
+		occ := countOccupied(neighbors)
+		switch {
+		case seat == 'L' && occ == 0:
+			return '#'
+		case seat == '#' && occ >= tooCrowdedThreshold:
+			return 'L'
+		}
+		return seat
 	}
-	return input, nil
 }
 
-// x and y are seat coordinates
-func occupiedCountAroundSeat(x, y int, seats []string) int {
+func countOccupied(seats []rune) int {
 	var occupied int
-	for i := y - 1; i <= y+1; i++ {
-		if i < 0 || i >= len(seats) {
-			// Out of bounds.
-			continue
-		}
-		for j := x - 1; j <= x+1; j++ {
-			if j < 0 || j >= len(seats[i]) {
-				// Out of bounds.
-				continue
-			}
-			if i == y && j == x {
-				// This is the seat we're summing around: skip it.
-				continue
-			}
-			if seats[i][j] == '#' {
-				occupied++
-			}
+	for _, seat := range seats {
+		if seat == '#' {
+			occupied++
 		}
 	}
 	return occupied
 }
 
-func nextIteration(
-	rows []string,
-	occCalcFunc func(int, int, []string) int,
-	tooCrowdedThreshold int,
-) ([]string, int) {
-	var (
-		next          []string
-		occupiedCount int
-	)
-	for y, row := range rows {
-		var newRow strings.Builder
-		for x, seat := range row {
-			if seat == '.' {
-				// Skip the floor!
-				newRow.WriteRune('.')
-				continue
-			}
-			occ := occCalcFunc(x, y, rows)
-			var newSeat rune
-			switch {
-			case seat == 'L' && occ == 0:
-				newSeat = '#'
-			case seat == '#' && occ >= tooCrowdedThreshold:
-				newSeat = 'L'
-			default:
-				newSeat = seat
-			}
-			newRow.WriteRune(newSeat)
-			if newSeat == '#' {
-				occupiedCount++
-			}
-		}
-		next = append(next, newRow.String())
+// settle runs the seating rules until nobody moves, and returns how many
+// seats end up occupied.
+func settle(seats *grid.Grid, nb automaton.Neighborhood, tooCrowdedThreshold int, log *trace.Logger) (int, error) {
+	a := &automaton.Automaton{
+		Neighborhood: nb,
+		Rule:         seatRule(tooCrowdedThreshold),
 	}
-	return next, occupiedCount
-}
-
-func part1(rows Rows) (int, error) {
-	var occupiedCount int
-
-	for numRounds := 0; ; numRounds++ {
-		var newOccupiedCount int
-		rows, newOccupiedCount = nextIteration(rows, occupiedCountAroundSeat, 4)
-
-		trace("%v", rows)
-		trace("after round %d: %d occupied seats\n", numRounds+1, occupiedCount)
-		if newOccupiedCount == occupiedCount {
-			break
-		}
-		occupiedCount = newOccupiedCount
+	final, rounds, err := a.Fixpoint(automaton.DenseFromGrid(seats), 0)
+	if err != nil {
+		return 0, err
 	}
+	log.Debugf("settled after %d rounds:\n%v", rounds, final)
+	return final.(*automaton.Dense).Count('#'), nil
+}
 
-	return occupiedCount, nil
+func part1(seats *grid.Grid, log *trace.Logger) (int, error) {
+	return settle(seats, automaton.Moore(2), 4, log)
 }
 
-func occupiedCountRays(x, y int, rows []string) int {
-	var occupied int
-	for dirY := -1; dirY <= 1; dirY++ {
-		for dirX := -1; dirX <= 1; dirX++ {
-			nearestX, nearestY, err := findNearestSeatInDirection(x, y, dirX, dirY, rows)
+// lineOfSight returns a neighborhood of the nearest seat in each of the eight
+// directions, looking past the floor. Since the floor never changes, this is
+// worked out once up front.
+func lineOfSight(seats *grid.Grid) automaton.Neighborhood {
+	visible := make(map[automaton.Coord][]automaton.Coord)
+	seats.Each(func(p grid.Point, _ rune) {
+		var coords []automaton.Coord
+		for _, dir := range grid.Adjacent {
+			nearest, err := findNearestSeatInDirection(seats, p, dir)
 			if err != nil {
 				// No seat found in this direction, so continue.
 				continue
 			}
-			if rows[nearestY][nearestX] == '#' {
-				occupied++
-			}
+			coords = append(coords, automaton.Coord{nearest.X, nearest.Y})
 		}
+		visible[automaton.Coord{p.X, p.Y}] = coords
+	})
+	return func(c automaton.Coord) []automaton.Coord {
+		return visible[c]
 	}
-	return occupied
 }
 
-func findNearestSeatInDirection(x, y, dirX, dirY int, rows []string) (int, int, error) {
-	var (
-		foundX int
-		foundY int
-	)
-	if dirX == 0 && dirY == 0 {
-		return 0, 0, errors.New("no direction provided")
+func findNearestSeatInDirection(seats *grid.Grid, p, dir grid.Point) (grid.Point, error) {
+	if dir == (grid.Point{}) {
+		return grid.Point{}, errors.New("no direction provided")
 	}
-	for n := 1; ; n++ {
-		checkY := y + (n * dirY)
-		if checkY < 0 || checkY >= len(rows) {
-			return 0, 0, errors.New("out of bounds (y)")
-		}
-
-		checkX := x + (n * dirX)
-		if checkX < 0 || checkX >= len(rows[checkY]) {
-			return 0, 0, errors.New("out of bounds (x)")
-		}
-
-		checkSeat := rows[checkY][checkX]
-		if checkSeat != '.' {
-			foundX = checkX
-			foundY = checkY
-			break
-		}
-	}
-	return foundX, foundY, nil
-}
-
-type Rows []string
-
-func (r Rows) String() string {
-	s := ""
-	for _, row := range r {
-		s += row + "\n"
+	isSeat := func(r rune) bool { return r != '.' }
+	found, ok := seats.Ray(p, dir, isSeat)
+	if !ok {
+		return grid.Point{}, errors.New("out of bounds")
 	}
-	return s[:len(s)-1]
+	return found, nil
 }
 
-func part2(rows Rows) (int, error) {
-	var occupiedCount int
-
-	for numRounds := 0; ; numRounds++ {
-		var newOccupiedCount int
-		rows, newOccupiedCount = nextIteration(rows, occupiedCountRays, 5)
-
-		trace("%v", rows)
-		trace("after round %d: %d occupied seats\n", numRounds+1, occupiedCount)
-
-		if newOccupiedCount == occupiedCount {
-			break
-		}
-		occupiedCount = newOccupiedCount
-	}
-
-	return occupiedCount, nil
+func part2(seats *grid.Grid, log *trace.Logger) (int, error) {
+	return settle(seats, lineOfSight(seats), 5, log)
 }
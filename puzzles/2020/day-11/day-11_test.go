@@ -2,9 +2,24 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"testing"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/automaton"
+	"github.com/ianfoo/advent-of-code-2020/internal/grid"
+	"github.com/ianfoo/advent-of-code-2020/internal/input"
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
 )
 
+func mustGrid(t *testing.T, lines []string) *grid.Grid {
+	t.Helper()
+	g, err := grid.FromLines(lines)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return g
+}
+
 func TestOccupiedAroundSeat(t *testing.T) {
 	testInput := []string{
 		"######",
@@ -21,9 +36,11 @@ func TestOccupiedAroundSeat(t *testing.T) {
 		{x: 1, y: 2, want: 2},
 		{x: 4, y: 3, want: 2},
 	}
+	state := automaton.DenseFromGrid(mustGrid(t, testInput))
 	for _, tc := range tt {
 		t.Run(fmt.Sprintf("x:%d y:%d", tc.x, tc.y), func(t *testing.T) {
-			if got := occupiedCountAroundSeat(tc.x, tc.y, testInput); got != tc.want {
+			neighbors := automaton.Neighbors(state, automaton.Moore(2), automaton.Coord{tc.x, tc.y})
+			if got := countOccupied(neighbors); got != tc.want {
 				t.Fatalf("expected %d occupied for %d, %d, but got %d", tc.want, tc.x, tc.y, got)
 			}
 		})
@@ -134,7 +151,10 @@ var grids = []struct {
 func TestOccupiedCountRays(t *testing.T) {
 	for _, tc := range grids {
 		t.Run(tc.pattern[0], func(t *testing.T) {
-			if got := occupiedCountRays(tc.x, tc.y, tc.pattern); got != tc.occupied {
+			seats := mustGrid(t, tc.pattern)
+			state := automaton.DenseFromGrid(seats)
+			neighbors := automaton.Neighbors(state, lineOfSight(seats), automaton.Coord{tc.x, tc.y})
+			if got := countOccupied(neighbors); got != tc.occupied {
 				t.Fatalf("expected %d seats occupied, but got %d", tc.occupied, got)
 			}
 		})
@@ -143,6 +163,7 @@ func TestOccupiedCountRays(t *testing.T) {
 
 func TestFindNearestSeatInDirection(t *testing.T) {
 	g := grids[0]
+	seats := mustGrid(t, g.pattern)
 	for x := -1; x <= 1; x++ {
 		for y := -1; y <= 1; y++ {
 			if x == 0 && y == 0 {
@@ -150,7 +171,7 @@ func TestFindNearestSeatInDirection(t *testing.T) {
 				continue
 			}
 			t.Run(fmt.Sprintf("dirX:%d dirY:%d", x, y), func(t *testing.T) {
-				foundX, foundY, err := findNearestSeatInDirection(g.x, g.y, x, y, g.pattern)
+				found, err := findNearestSeatInDirection(seats, grid.Point{X: g.x, Y: g.y}, grid.Point{X: x, Y: y})
 				c := g.closest[x][y]
 				if err != nil {
 					if c.expectErr {
@@ -158,13 +179,49 @@ func TestFindNearestSeatInDirection(t *testing.T) {
 					}
 					t.Fatalf("unexpected error: %v", err)
 				}
-				if c.x != foundX || c.y != foundY {
+				if c.x != found.X || c.y != found.Y {
 					t.Fatalf(
 						"expected closest coordinates (%d,%d), but got (%d,%d)",
 						c.x, c.y,
-						foundX, foundY)
+						found.X, found.Y)
 				}
 			})
 		}
 	}
 }
+
+func TestSample(t *testing.T) {
+	f, err := os.Open("sample-input.txt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+	rows, err := input.Grid(f)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	seats, err := grid.FromRunes(rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tt := []struct {
+		name string
+		part func(*grid.Grid, *trace.Logger) (int, error)
+		want int
+	}{
+		{name: "part 1", part: part1, want: 37},
+		{name: "part 2", part: part2, want: 26},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.part(seats, trace.Discard)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("expected %d occupied seats, but got %d", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"math/rand"
+	"strings"
+)
+
+// generate makes a square seat layout size seats across, with a seat in
+// about three squares out of four.
+func generate(rng *rand.Rand, size int) []string {
+	lines := make([]string, size)
+	for i := range lines {
+		var b strings.Builder
+		for j := 0; j < size; j++ {
+			if rng.Intn(4) == 0 {
+				b.WriteByte('.')
+			} else {
+				b.WriteByte('L')
+			}
+		}
+		lines[i] = b.String()
+	}
+	return lines
+}
@@ -3,81 +3,40 @@
 package main
 
 import (
-	"bufio"
-	"flag"
 	"fmt"
 	"io"
-	"log"
-	"os"
 	"strconv"
-)
 
-var verbose bool
+	"github.com/ianfoo/advent-of-code-2020/internal/answer"
+	"github.com/ianfoo/advent-of-code-2020/internal/input"
+	"github.com/ianfoo/advent-of-code-2020/internal/runner"
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
+)
 
 func main() {
-	flag.BoolVar(&verbose, "verbose", false, "Print more detail.")
-	flag.Parse()
-
-	log.SetFlags(log.Ltime | log.Lmicroseconds)
-
-	if err := run(os.Stdin); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
-	}
+	runner.Solution{Year: 2020, Day: 12, Solve: run, Generate: generate}.Main()
 }
 
-func trace(format string, params ...interface{}) {
-	if verbose {
-		fmt.Printf(format+"\n", params...)
-	}
-}
-
-func run(r io.Reader) error {
-	input, err := readInput(r)
+func run(r io.Reader, s *runner.Session) error {
+	lines, err := input.Lines(r)
 	if err != nil {
-		return fmt.Errorf("reading input: %w", err)
+		return fmt.Errorf("reading lines: %w", err)
 	}
 
-	var part1Result int
-	{
-		var err error
-		part1Result, err = part1(input)
-		if err != nil {
-			return fmt.Errorf("part 1: %w", err)
-		}
-	}
+	s.Part(1, func(log *trace.Logger) (answer.Answer, error) {
+		result, err := part1(lines, log)
+		return answer.Int(result), err
+	})
 
-	var part2Result int
-	{
-		var err error
-		part2Result, err = part2(input)
-		if err != nil {
-			return fmt.Errorf("part 2: %w", err)
-		}
-	}
-
-	fmt.Printf("Part 1: %d\n", part1Result)
-	fmt.Printf("Part 2: %d\n", part2Result)
+	s.Part(2, func(log *trace.Logger) (answer.Answer, error) {
+		result, err := part2(lines)
+		return answer.Int(result), err
+	})
 
 	return nil
 }
 
-func readInput(r io.Reader) ([]string, error) {
-	var (
-		s     = bufio.NewScanner(r)
-		input []string
-	)
-	for s.Scan() {
-		line := s.Text()
-		input = append(input, line)
-	}
-	if err := s.Err(); err != nil {
-		return nil, err
-	}
-	return input, nil
-}
-
-func part1(input []string) (int, error) {
+func part1(input []string, log *trace.Logger) (int, error) {
 	var (
 		movement          = make(map[string]int)
 		currentDir string = "E"
@@ -128,7 +87,7 @@ func part1(input []string) (int, error) {
 		default:
 			return 0, fmt.Errorf("invalid input direction %s on line %d", val, i+1)
 		}
-		trace("%v", movement)
+		log.Tracef("%v", movement)
 	}
 	eastWest := movement["E"] - movement["W"]
 	if eastWest < 0 {
@@ -138,8 +97,8 @@ func part1(input []string) (int, error) {
 	if northSouth < 0 {
 		northSouth *= -1
 	}
-	fmt.Printf("movement matrix: %+v\n", movement)
-	fmt.Printf("east-west: %d, north-south: %d\n", eastWest, northSouth)
+	log.Debugf("movement matrix: %+v", movement)
+	log.Debugf("east-west: %d, north-south: %d", eastWest, northSouth)
 
 	return eastWest + northSouth, nil
 }
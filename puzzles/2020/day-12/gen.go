@@ -0,0 +1,21 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// generate makes size navigation instructions. Turns are always a multiple
+// of 90 degrees.
+func generate(rng *rand.Rand, size int) []string {
+	lines := make([]string, size)
+	for i := range lines {
+		switch action := "NSEWLRF"[rng.Intn(7)]; action {
+		case 'L', 'R':
+			lines[i] = fmt.Sprintf("%c%d", action, 90*(1+rng.Intn(3)))
+		default:
+			lines[i] = fmt.Sprintf("%c%d", action, 1+rng.Intn(100))
+		}
+	}
+	return lines
+}
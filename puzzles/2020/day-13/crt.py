@@ -79,11 +79,14 @@ def main():
     buses = []
     rems = []
     schedule = lines[1].split(",")
-    for rem, route in enumerate(schedule):
+    for offset, route in enumerate(schedule):
+        route = route.strip()
         if route == "x":
             continue
+        # Bus departs offset minutes after the answer, so the answer is
+        # -offset modulo the bus ID.
         buses.append(int(route))
-        rems.append(rem)
+        rems.append(-offset % int(route))
 
     print(buses)
     print(rems)
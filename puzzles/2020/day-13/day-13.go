@@ -3,86 +3,59 @@
 package main
 
 import (
-	"bufio"
-	"flag"
 	"fmt"
 	"io"
-	"log"
-	"os"
 	"strconv"
 	"strings"
-)
 
-var verbose bool
+	"github.com/ianfoo/advent-of-code-2020/internal/answer"
+	"github.com/ianfoo/advent-of-code-2020/internal/difftest"
+	"github.com/ianfoo/advent-of-code-2020/internal/input"
+	"github.com/ianfoo/advent-of-code-2020/internal/numtheory"
+	"github.com/ianfoo/advent-of-code-2020/internal/runner"
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
+)
 
 func main() {
-	flag.BoolVar(&verbose, "verbose", false, "Print more detail.")
-	flag.Parse()
-
-	log.SetFlags(log.Ltime | log.Lmicroseconds)
-
-	if err := run(os.Stdin); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
-	}
+	runner.Solution{
+		Year:     2020,
+		Day:      13,
+		Solve:    run,
+		Generate: generate,
+		Shrink:   difftest.Chain(difftest.Items(1, ","), difftest.Replace(1, ",", "x")),
+	}.Main()
 }
 
-func trace(format string, params ...interface{}) {
-	if verbose {
-		fmt.Printf(format+"\n", params...)
-	}
-}
-
-func run(r io.Reader) error {
-	input, err := readInput(r)
+func run(r io.Reader, s *runner.Session) error {
+	lines, err := input.Lines(r)
 	if err != nil {
-		return fmt.Errorf("reading input: %w", err)
+		return fmt.Errorf("reading lines: %w", err)
 	}
 
-	earliest, buses, err := getEarliestDepartureAndBuses(input)
+	earliest, buses, err := getEarliestDepartureAndBuses(lines)
 	if err != nil {
-		return fmt.Errorf("parsing input: %w", err)
+		return fmt.Errorf("parsing lines: %w", err)
 	}
 
-	var part1Result int
-	{
-		var err error
-		part1Result = part1(earliest, buses)
-		if err != nil {
-			return fmt.Errorf("part 1: %w", err)
-		}
-	}
+	s.Part(1, func(log *trace.Logger) (answer.Answer, error) {
+		return answer.Int(part1(earliest, buses, log)), nil
+	})
 
-	var part2Result int
-	{
-		var err error
-		part2Result, err = part2(input)
-		if err != nil {
-			return fmt.Errorf("part 2: %w", err)
+	s.Part(2, func(log *trace.Logger) (answer.Answer, error) {
+		result, err := part2(lines)
+		return answer.Int64(result), err
+	})
+	s.Impl(2, "brute-force", func(log *trace.Logger) (answer.Answer, error) {
+		if err := checkBruteForce(lines, buses); err != nil {
+			return answer.Answer{}, err
 		}
-	}
-
-	fmt.Printf("Part 1: %d\n", part1Result)
-	fmt.Printf("Part 2: %d\n", part2Result)
+		result, err := part2BruteForce(lines, log)
+		return answer.Int(result), err
+	})
 
 	return nil
 }
 
-func readInput(r io.Reader) ([]string, error) {
-	var (
-		s     = bufio.NewScanner(r)
-		input []string
-	)
-	for s.Scan() {
-		line := s.Text()
-		input = append(input, line)
-	}
-	if err := s.Err(); err != nil {
-		return nil, err
-	}
-	return input, nil
-}
-
 func getEarliestDepartureAndBuses(input []string) (int, []int, error) {
 	earliest, err := strconv.Atoi(input[0])
 	if err != nil {
@@ -105,51 +78,120 @@ func getEarliestDepartureAndBuses(input []string) (int, []int, error) {
 	return earliest, buses, nil
 }
 
-func part1(earliest int, buses []int) int {
+func part1(earliest int, buses []int, log *trace.Logger) int {
 	minWait := earliest
 	bestBus := 0
 	for _, bus := range buses {
 		mod := earliest % bus
 		arrival := earliest - mod + bus
 		wait := arrival - earliest
-		trace("bus %d arrives %d minutes after %d", bus, mod, earliest)
+		log.Debugf("bus %d arrives %d minutes after %d", bus, mod, earliest)
 		if wait < minWait {
 			bestBus = bus
 			minWait = wait
 		}
 	}
-	trace("bus: %d wait: %d", bestBus, minWait)
+	log.Debugf("bus: %d wait: %d", bestBus, minWait)
 	return minWait * bestBus
 }
 
-func part2(input []string) (int, error) {
-	busesStr := strings.Split(input[1], ",")
+// parseSchedule reads the bus IDs in the order they are listed, with -1 for
+// the out of service buses marked "x".
+func parseSchedule(line string) ([]int, error) {
+	busesStr := strings.Split(line, ",")
 	buses := make([]int, 0, len(busesStr))
-	maxBus := 0
-	maxBusIndex := 0
-	for i, busStr := range busesStr {
+	for _, busStr := range busesStr {
 		if busStr == "x" {
 			buses = append(buses, -1)
 			continue
 		}
 		bus, err := strconv.Atoi(busStr)
 		if err != nil {
-			return 0, fmt.Errorf("invalid bus ID: %w", err)
+			return nil, fmt.Errorf("invalid bus ID: %w", err)
 		}
 		buses = append(buses, bus)
+	}
+	return buses, nil
+}
+
+// part2 finds the earliest time t at which each bus leaves as many minutes
+// after t as its position in the list. Bus b at position i leaving at t+i
+// means t = -i (mod b), so this is the Chinese remainder theorem.
+func part2(input []string) (int64, error) {
+	buses, err := parseSchedule(input[1])
+	if err != nil {
+		return 0, err
+	}
+
+	var rems, mods []int64
+	for i, b := range buses {
+		if b == -1 {
+			continue
+		}
+		rem, err := numtheory.Mod(int64(-i), int64(b))
+		if err != nil {
+			return 0, fmt.Errorf("bus %d: %w", b, err)
+		}
+		rems = append(rems, rem)
+		mods = append(mods, int64(b))
+	}
+	t, _, err := numtheory.GeneralizedCRT(rems, mods)
+	if err != nil {
+		return 0, err
+	}
+	return t, nil
+}
+
+// The brute force solution is only worth running when it won't have to check
+// more departures than this.
+const maxBruteForceSteps = 10000000
+
+// checkBruteForce returns runner.ErrSkip if the brute force solution would take
+// too long, judging by the answer worked out the quick way.
+func checkBruteForce(input []string, buses []int) error {
+	t, err := part2(input)
+	if err != nil {
+		return err
+	}
+	maxBus := 0
+	for _, b := range buses {
+		if b > maxBus {
+			maxBus = b
+		}
+	}
+	if steps := t / int64(maxBus); steps > maxBruteForceSteps {
+		return fmt.Errorf("brute force would check %d departures: %w", steps, runner.ErrSkip)
+	}
+	return nil
+}
+
+// part2BruteForce finds the same answer as part2 by checking every departure
+// of the bus with the longest route. It works, but runs forever with actual
+// input.
+func part2BruteForce(input []string, log *trace.Logger) (int, error) {
+	buses, err := parseSchedule(input[1])
+	if err != nil {
+		return 0, err
+	}
+	maxBus := 0
+	maxBusIndex := 0
+	for i, bus := range buses {
 		if bus > maxBus {
 			maxBus = bus
 			maxBusIndex = i
 		}
 	}
 
-	fmt.Printf("%d bus routes\n", len(buses))
+	log.Debugf("%d bus routes", len(buses))
 	var result int
 
+	// Start with the first departure of the longest route that leaves room
+	// for the buses before it in the list.
+	start := (maxBusIndex + maxBus - 1) / maxBus * maxBus
+
 LOOP:
-	// My attempt. Works, but runs forever with actual input.
-	for t := 0; ; t += maxBus {
-		trace("t: %d", t)
+	for t := start; ; t += maxBus {
+		log.Tracef("t: %d", t)
 		for offset, b := range buses {
 			if b == -1 {
 				continue
@@ -157,71 +199,13 @@ LOOP:
 			arrival := t + offset - maxBusIndex
 			offBy := arrival % b
 			if offBy != 0 {
-				// trace("t: %d bus %d does not arrive at %d (off by %d): TRY AGAIN", t, b, arrival, offBy)
 				continue LOOP
 			}
-			trace("bus %d arrives at %d", b, arrival)
+			log.Tracef("bus %d arrives at %d", b, arrival)
 		}
 		result = t - maxBusIndex
 		break
 	}
 
 	return result, nil
-
-	/*
-		Chinese remainder theorem solution that doesn't work.
-		nums := make([]int, 0, len(buses))
-		rem := make([]int, 0, len(buses))
-		for i, b := range buses {
-			if b == -1 {
-				continue
-			}
-			nums = append(nums, b)
-			rem = append(rem, i)
-		}
-		result := crt(nums, rem)
-		return result, nil
-	*/
-}
-
-// Absolutely no idea. Translated from C++ from
-// https://www.geeksforgeeks.org/chinese-remainder-theorem-set-2-implementation/
-func modInv(a, m int) int {
-	if m == 1 {
-		return 0
-	}
-
-	m0 := m
-	x0, x1 := 0, 1
-	for a > 1 {
-		q := a / m
-		t := m
-		m = a % m
-		a = t
-		x0 = x1 - q*x0
-		x1 = t
-	}
-	if x1 < 0 {
-		x1 += m0
-	}
-	return x1
-}
-
-// Translated from this after finding pages of mathematical gobledygook
-// describing the Chinese Remainder Theorem but nothing actionable (i.e., code).
-//
-// https://www.geeksforgeeks.org/chinese-remainder-theorem-set-2-implementation/
-func crt(nums, rem []int) int {
-	prod := 1
-	for _, n := range nums {
-		prod *= n
-	}
-
-	result := 0
-	for i, bus := range nums {
-		pp := prod / bus
-		result += rem[i] * modInv(pp, bus) * pp
-	}
-
-	return result % prod
 }
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/ianfoo/advent-of-code-2020/internal/input"
+	"github.com/ianfoo/advent-of-code-2020/internal/runner"
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
+)
+
+func TestPart2(t *testing.T) {
+	tt := []struct {
+		file string
+		want int64
+	}{
+		{file: "sample-input.txt", want: 1068781},
+		{file: "sample-2.txt", want: 754018},
+		{file: "sample-3.txt", want: 779210},
+		{file: "sample-4.txt", want: 1261476},
+		{file: "sample-5.txt", want: 1202161486},
+		// The longest route is late in the list, after the answer.
+		{file: "sample-offset.txt", want: 20},
+	}
+	for _, tc := range tt {
+		t.Run(tc.file, func(t *testing.T) {
+			f, err := os.Open(tc.file)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer f.Close()
+			lines, err := input.Lines(f)
+			if err != nil {
+				t.Fatalf("unexpected error reading input: %v", err)
+			}
+
+			if got, err := part2(lines); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if got != tc.want {
+				t.Errorf("expected %d, but got %d", tc.want, got)
+			}
+			if got, err := part2BruteForce(lines, trace.Discard); err != nil {
+				t.Errorf("brute force: unexpected error: %v", err)
+			} else if int64(got) != tc.want {
+				t.Errorf("brute force: expected %d, but got %d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDiffTest(t *testing.T) {
+	sol := runner.Solution{Solve: run, Generate: generate}
+	if f := sol.DiffTest(runner.DiffOptions{Runs: 50, Size: 20, Seed: 1}); f != nil {
+		t.Fatalf("%v\n%v", f, f.Input)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// generate makes a schedule with size slots, a few of them buses with
+// distinct prime IDs, so that they're coprime. The IDs are kept small enough
+// for the brute force solution to finish quickly.
+func generate(rng *rand.Rand, size int) []string {
+	primes := []int{2, 3, 5, 7, 11, 13, 17, 19, 23}
+	if size < 2 {
+		size = 2
+	}
+	var (
+		slots = make([]string, size)
+		ids   = rng.Perm(len(primes))[:min(size, 2+rng.Intn(3))]
+		where = rng.Perm(size)
+	)
+	for i := range slots {
+		slots[i] = "x"
+	}
+	for i, id := range ids {
+		slots[where[i]] = strconv.Itoa(primes[id])
+	}
+	return []string{strconv.Itoa(1 + rng.Intn(1000)), strings.Join(slots, ",")}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -3,86 +3,45 @@
 package main
 
 import (
-	"bufio"
-	"flag"
 	"fmt"
 	"io"
-	"log"
-	"os"
+	"regexp"
 	"strconv"
-	"strings"
-)
 
-var verbose bool
+	"github.com/ianfoo/advent-of-code-2020/internal/answer"
+	"github.com/ianfoo/advent-of-code-2020/internal/input"
+	"github.com/ianfoo/advent-of-code-2020/internal/runner"
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
+)
 
 func main() {
-	flag.BoolVar(&verbose, "verbose", false, "Print more detail.")
-	flag.Parse()
-
-	log.SetFlags(log.Ltime | log.Lmicroseconds)
-
-	if err := run(os.Stdin); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
-	}
+	runner.Solution{Year: 2020, Day: 14, Solve: run, Generate: generate}.Main()
 }
 
-func trace(format string, params ...interface{}) {
-	if verbose {
-		fmt.Printf(format+"\n", params...)
-	}
-}
-
-func run(r io.Reader) error {
-	input, err := readInput(r)
+func run(r io.Reader, s *runner.Session) error {
+	lines, err := input.Lines(r)
 	if err != nil {
-		return fmt.Errorf("reading input: %w", err)
+		return fmt.Errorf("reading lines: %w", err)
 	}
 
-	instructions, err := parseProgram(input)
+	instructions, err := parseProgram(lines)
 	if err != nil {
 		return fmt.Errorf("parsing program: %w", err)
 	}
 
-	var part1Result int64
-	{
-		var err error
-		part1Result, err = part1(instructions)
-		if err != nil {
-			return fmt.Errorf("part 1: %w", err)
-		}
-	}
-
-	var part2Result int64
-	{
-		var err error
-		part2Result, err = part2(instructions)
-		if err != nil {
-			return fmt.Errorf("part 2: %w", err)
-		}
-	}
+	s.Part(1, func(log *trace.Logger) (answer.Answer, error) {
+		result, err := part1(instructions)
+		return answer.Int64(result), err
+	})
 
-	fmt.Printf("Part 1: %d\n", part1Result)
-	fmt.Printf("Part 2: %d\n", part2Result)
+	s.Part(2, func(log *trace.Logger) (answer.Answer, error) {
+		result, err := part2(instructions, log)
+		return answer.Int64(result), err
+	})
 
 	return nil
 }
 
-func readInput(r io.Reader) ([]string, error) {
-	var (
-		s     = bufio.NewScanner(r)
-		input []string
-	)
-	for s.Scan() {
-		line := s.Text()
-		input = append(input, line)
-	}
-	if err := s.Err(); err != nil {
-		return nil, err
-	}
-	return input, nil
-}
-
 type Instruction struct {
 	Type    int
 	Payload string
@@ -94,44 +53,39 @@ const (
 	SetMemInstruction
 )
 
-func parseProgram(input []string) ([]Instruction, error) {
-	instructions := make([]Instruction, 0, len(input))
-	for i, line := range input {
-		tokens := strings.Split(line, " = ")
-		if len(tokens) != 2 {
-			return nil, fmt.Errorf("invalid input: %q", line)
-		}
+// Match instruction lines, which either set the mask or write to memory:
+// mask = [mask]
+// mem[[addr]] = [value]
+var instructionRegexp = regexp.MustCompile(`^(?:mask = (?P<mask>\S+)|mem\[(?P<addr>\d+)\] = (?P<value>\S+))$`)
+
+// instructionLine is an instruction as written in the program. Mask is empty
+// for a write to memory.
+type instructionLine struct {
+	Mask  string `aoc:"mask"`
+	Addr  int64  `aoc:"addr"`
+	Value string `aoc:"value"`
+}
 
-		op, payload := tokens[0], tokens[1]
+func parseProgram(lines []string) ([]Instruction, error) {
+	var decoded []instructionLine
+	if err := input.NewDecoder(instructionRegexp).DecodeAll(lines, &decoded); err != nil {
+		return nil, err
+	}
 
-		if op == "mask" {
+	instructions := make([]Instruction, 0, len(decoded))
+	for _, d := range decoded {
+		if d.Mask != "" {
 			instr := Instruction{
 				Type:    SetMaskInstruction,
-				Payload: payload,
+				Payload: d.Mask,
 			}
 			instructions = append(instructions, instr)
 			continue
 		}
-		if !strings.HasPrefix(op, "mem") {
-			return nil, fmt.Errorf("invalid input: %q", line)
-		}
-
-		var addr int64
-		{
-			addrStr := op
-			addrStr = strings.TrimPrefix(addrStr, "mem[")
-			addrStr = strings.TrimSuffix(addrStr, "]")
-
-			var err error
-			addr, err = strconv.ParseInt(addrStr, 0, 64)
-			if err != nil {
-				return nil, fmt.Errorf("invalid memory address %q on line %d: %w", addrStr, i+1, err)
-			}
-		}
 		instr := Instruction{
 			Type:    SetMemInstruction,
-			Addr:    addr,
-			Payload: payload,
+			Addr:    d.Addr,
+			Payload: d.Value,
 		}
 		instructions = append(instructions, instr)
 	}
@@ -254,7 +208,6 @@ func (m MaskV2) setValueAtAddresses(exp int, sum, addr, val int64, mem map[int64
 	if 1<<exp > m.FloatBits {
 		newAddr := addr + sum
 		mem[newAddr] = val
-		trace("setting value %d at address %d", val, newAddr)
 		return
 	}
 	m.setValueAtAddresses(exp+1, sum, addr, val, mem)
@@ -264,7 +217,7 @@ func (m MaskV2) setValueAtAddresses(exp int, sum, addr, val int64, mem map[int64
 	}
 }
 
-func part2(instructions []Instruction) (int64, error) {
+func part2(instructions []Instruction, log *trace.Logger) (int64, error) {
 	var (
 		mask MaskV2
 		mem  = make(map[int64]int64)
@@ -276,7 +229,7 @@ func part2(instructions []Instruction) (int64, error) {
 			if err != nil {
 				return 0, err
 			}
-			trace("new mask: %s", instr.Payload)
+			log.Tracef("new mask: %s", instr.Payload)
 			continue
 		}
 
@@ -289,6 +242,7 @@ func part2(instructions []Instruction) (int64, error) {
 				return 0, fmt.Errorf("invalid value %q on line %d: %w", valueStr, i+1, err)
 			}
 		}
+		log.Tracef("setting value %d at addresses floating from %d", value, instr.Addr)
 		mask.SetValueAtAddresses(instr.Addr, value, mem)
 	}
 
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// generate makes a program of about size instructions in blocks of a mask and
+// a few writes. Masks have at most six floating bits, so that the writes in
+// part 2 stay manageable.
+func generate(rng *rand.Rand, size int) []string {
+	var lines []string
+	for len(lines) < size {
+		mask := []byte(strings.Repeat("0", 36))
+		for i := range mask {
+			if rng.Intn(2) == 0 {
+				mask[i] = '1'
+			}
+		}
+		for _, i := range rng.Perm(len(mask))[:rng.Intn(7)] {
+			mask[i] = 'X'
+		}
+		lines = append(lines, "mask = "+string(mask))
+		for n := 1 + rng.Intn(4); n > 0; n-- {
+			lines = append(lines, fmt.Sprintf("mem[%d] = %d", rng.Intn(1<<16), rng.Int63n(1<<36)))
+		}
+	}
+	return lines
+}
@@ -3,101 +3,68 @@
 package main
 
 import (
-	"bufio"
-	"flag"
 	"fmt"
 	"io"
-	"log"
-	"os"
-	"strconv"
-	"strings"
-)
 
-var verbose bool
+	"github.com/ianfoo/advent-of-code-2020/internal/answer"
+	"github.com/ianfoo/advent-of-code-2020/internal/difftest"
+	"github.com/ianfoo/advent-of-code-2020/internal/input"
+	"github.com/ianfoo/advent-of-code-2020/internal/runner"
+	"github.com/ianfoo/advent-of-code-2020/internal/trace"
+)
 
 func main() {
-	flag.BoolVar(&verbose, "verbose", false, "Print more detail.")
-	flag.Parse()
-
-	log.SetFlags(log.Ltime | log.Lmicroseconds)
-
-	if err := run(os.Stdin); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
-	}
+	runner.Solution{
+		Year:     2020,
+		Day:      15,
+		Solve:    run,
+		Generate: generate,
+		Shrink:   difftest.Items(0, ","),
+	}.Main()
 }
 
-func trace(format string, params ...interface{}) {
-	if verbose {
-		fmt.Printf(format+"\n", params...)
-	}
-}
-
-func run(r io.Reader) error {
-	input, err := readInput(r)
+func run(r io.Reader, s *runner.Session) error {
+	startingNums, err := input.CommaInts(r)
 	if err != nil {
-		return fmt.Errorf("reading input: %w", err)
+		return fmt.Errorf("reading startingNums: %w", err)
 	}
 
-	var part1Result int
-	const part1LastTurn = 2020
-	{
-		var err error
-		part1Result, err = NthRoundNumber(input, part1LastTurn)
+	s.Part(1, func(log *trace.Logger) (answer.Answer, error) {
+		const lastTurn = 2020
+		result, err := NthRoundNumber(startingNums, lastTurn, log)
 		if err != nil {
-			return fmt.Errorf("part 1: %w", err)
+			return answer.Answer{}, err
 		}
-	}
-	fmt.Printf("Part 1: Number spoken during turn %d: %d\n", part1LastTurn, part1Result)
+		log.Infof("number spoken during turn %d: %d", lastTurn, result)
+		return answer.Int(result), nil
+	})
+	s.Impl(1, "naive", func(log *trace.Logger) (answer.Answer, error) {
+		const lastTurn = 2020
+		return answer.Int(nthRoundNumberNaive(startingNums, lastTurn)), nil
+	})
 
 	// NOTE: Part 2 ran for 6-7 seconds on my laptop, so there is surely a way
 	// to optimize this. Perhaps a pattern that can be detected and used to pick
 	// out the nth turn's number quickly.
-	const part2LastTurn = 30000000
-	var part2Result int
-	{
-		var err error
-		part2Result, err = NthRoundNumber(input, part2LastTurn)
+	s.Part(2, func(log *trace.Logger) (answer.Answer, error) {
+		const lastTurn = 30000000
+		result, err := NthRoundNumber(startingNums, lastTurn, log)
 		if err != nil {
-			return fmt.Errorf("part 2: %w", err)
+			return answer.Answer{}, err
 		}
-	}
-	fmt.Printf("Part 2: Number spoken during turn %d: %d\n", part2LastTurn, part2Result)
+		log.Infof("number spoken during turn %d: %d", lastTurn, result)
+		return answer.Int(result), nil
+	})
 
 	return nil
 }
 
-func readInput(r io.Reader) ([]int, error) {
-	var (
-		s     = bufio.NewScanner(r)
-		input []string
-	)
-	for s.Scan() {
-		line := s.Text()
-		input = append(input, line)
-	}
-	if err := s.Err(); err != nil {
-		return nil, err
-	}
-
-	numStr := strings.Split(input[0], ",")
-	nums := make([]int, 0, len(numStr))
-	for _, s := range numStr {
-		n, err := strconv.Atoi(s)
-		if err != nil {
-			return nil, err
-		}
-		nums = append(nums, n)
-	}
-	return nums, nil
-}
-
-func NthRoundNumber(startingNums []int, lastTurn int) (int, error) {
+func NthRoundNumber(startingNums []int, lastTurn int, log *trace.Logger) (int, error) {
 	m := make(map[int][2]int)
 
 	for i, n := range startingNums {
 		turn := i + 1
-		trace("[turn %3d] starting number: %d", turn, n)
+		log.Debugf("[turn %3d] starting number: %d", turn, n)
 		m[n] = [2]int{turn, 0}
 	}
 
@@ -111,7 +78,7 @@ func NthRoundNumber(startingNums []int, lastTurn int) (int, error) {
 			age = 0
 		}
 
-		trace("[turn %3d] mostRecent: %d lastMentions: %v age: %d", turn, mostRecent, lastMentions, age)
+		log.Tracef("[turn %3d] mostRecent: %d lastMentions: %v age: %d", turn, mostRecent, lastMentions, age)
 
 		// Update history for number we're saying now.
 		ageMostRecent := m[age]
@@ -123,3 +90,23 @@ func NthRoundNumber(startingNums []int, lastTurn int) (int, error) {
 
 	return mostRecent, nil
 }
+
+// nthRoundNumberNaive plays the game by looking back through every number
+// spoken so far, to check NthRoundNumber.
+func nthRoundNumberNaive(startingNums []int, lastTurn int) int {
+	spoken := append([]int(nil), startingNums...)
+	for len(spoken) < lastTurn {
+		var (
+			last = len(spoken) - 1
+			next = 0
+		)
+		for i := last - 1; i >= 0; i-- {
+			if spoken[i] == spoken[last] {
+				next = last - i
+				break
+			}
+		}
+		spoken = append(spoken, next)
+	}
+	return spoken[lastTurn-1]
+}
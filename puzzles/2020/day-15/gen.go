@@ -0,0 +1,19 @@
+package main
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// generate makes a list of up to size distinct starting numbers. Part 2 plays
+// thirty million turns whatever the input, so use -part 1 with -difftest to
+// check more than a few inputs.
+func generate(rng *rand.Rand, size int) []string {
+	n := 1 + rng.Intn(size)
+	nums := make([]string, n)
+	for i, x := range rng.Perm(2 * n)[:n] {
+		nums[i] = strconv.Itoa(x)
+	}
+	return []string{strings.Join(nums, ",")}
+}